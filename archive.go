@@ -0,0 +1,129 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/archive"
+)
+
+// pruneInterval is the interval the bundles of the archive which are
+// older than the retention are removed at, unless the retention is
+// shorter.
+const pruneInterval = time.Minute
+
+// archive writes the output and the final status of the process of the
+// record to the archive of the Box, and removes the bundles which are no
+// longer within the retention of the archive.
+func (b *Box) archive(rec record) error {
+	status, err := json.Marshal(rec.status())
+	if err != nil {
+		return err
+	}
+
+	b.archiveMu.Lock()
+	defer b.archiveMu.Unlock()
+
+	err = archive.Write(b.archiveDir, rec.ID, status, rec.Output)
+	if err != nil {
+		return err
+	}
+
+	return archive.Prune(b.archiveDir, b.retention.Age, b.retention.Bytes)
+}
+
+// prune removes the bundles of the archive which are no longer within
+// the retention of the archive at every interval, until the context of
+// the Box is done. Bundles only expire when the retention has an age.
+func (b *Box) prune() {
+	interval := pruneInterval
+	if b.retention.Age < interval {
+		interval = b.retention.Age
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			b.archiveMu.Lock()
+			// NOTE: I am purposely ignoring this error since the
+			// bundles are pruned again at the next interval.
+			_ = archive.Prune(b.archiveDir, b.retention.Age, b.retention.Bytes)
+			b.archiveMu.Unlock()
+		}
+	}
+}
+
+// ArchivedStatus returns the final status of the process for the given id
+// from the archive of the Box without reading its output, e.g. to check
+// the access to the process before its output is read using Archived.
+func (b *Box) ArchivedStatus(id string) (Status, error) {
+	if b.archiveDir == "" {
+		return Status{}, ErrProcessNotFound
+	}
+
+	data, err := archive.Status(b.archiveDir, id)
+	if errors.Is(err, archive.ErrNotFound) {
+		return Status{}, ErrProcessNotFound
+	}
+
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{}
+	err = json.Unmarshal(data, &status)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return status, nil
+}
+
+// Archived returns the final status of the process for the given id and
+// an OutputReader for reading the selected output streams of the process
+// from the archive of the Box, see WithArchive. The output of a process
+// is archived once it is released. Processes are looked up by their id
+// only, since aliases are reused once their process is released.
+//
+// The output is read to its end without following, so WithFollow has no
+// effect.
+func (b *Box) Archived(id string, streams Stream, opts ...OutputOption) (Status, *OutputReader, error) {
+	if streams == 0 || streams&^Combined != 0 {
+		return Status{}, nil, ErrInvalidStream
+	}
+
+	if b.archiveDir == "" {
+		return Status{}, nil, ErrProcessNotFound
+	}
+
+	data, output, err := archive.Open(b.archiveDir, id)
+	if errors.Is(err, archive.ErrNotFound) {
+		return Status{}, nil, ErrProcessNotFound
+	}
+
+	if err != nil {
+		return Status{}, nil, err
+	}
+
+	status := Status{}
+	err = json.Unmarshal(data, &status)
+	if err != nil {
+		_ = output.Close()
+		return Status{}, nil, err
+	}
+
+	out := newOutputReader(output, streams)
+	err = out.apply(opts...)
+	if err != nil {
+		_ = out.Close()
+		return Status{}, nil, err
+	}
+
+	return status, out, nil
+}
@@ -3,10 +3,20 @@ package sandbox
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"go.benjiv.com/sandbox/internal/cgroups"
+	"go.benjiv.com/sandbox/internal/network"
+	"go.benjiv.com/sandbox/internal/pty"
 )
 
 // New returns a sandbox environment after creating the parent
@@ -16,19 +26,123 @@ import (
 // sandbox environment will retain a process's information,
 // after process execution completes, in memory and on disk
 // before it's released.
-func New(ctx context.Context, releaseTimeout time.Duration) (*Box, error) {
-	tempDir, helper, err := deployHelper()
+//
+// Processes recorded in the journal of the directory of the
+// sandbox (see WithStateDir) are re-adopted.
+func New(
+	ctx context.Context,
+	releaseTimeout time.Duration,
+	opts ...BoxOption,
+) (*Box, error) {
+	limits, err := defaultLimits()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Box{
+	uidMap, gidMap := defaultIDMaps()
+
+	pool, err := network.NewPool(defaultSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Box{
 		ctx:            ctx,
-		tempDir:        tempDir,
-		helperPath:     helper,
-		catalog:        make(map[int]cmdInfo),
+		catalog:        make(map[string]cmdInfo),
+		aliases:        make(map[int64]string),
 		releaseTimeout: releaseTimeout,
-	}, nil
+		grace:          defaultGrace,
+		limits:         limits,
+		uidMap:         uidMap,
+		gidMap:         gidMap,
+		bridge:         defaultBridge,
+		pool:           pool,
+	}
+
+	for _, opt := range opts {
+		err = opt(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b.tempDir, b.helperPath, err = deployHelper(b.tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	b.lock, err = journal{dir: b.tempDir}.lock()
+	if err != nil {
+		return nil, err
+	}
+
+	b.cgroup, err = journal{dir: b.tempDir}.cgroup()
+	if err == nil {
+		err = b.adopt()
+	}
+
+	if err != nil {
+		_ = b.lock.Close()
+		return nil, err
+	}
+
+	// The directory is unlocked once the Box is done so
+	// another Box may adopt its processes.
+	go func() {
+		<-ctx.Done()
+		_ = b.lock.Close()
+	}()
+
+	if b.archiveDir != "" && b.retention.Age > 0 {
+		go b.prune()
+	}
+
+	return b, nil
+}
+
+// adopt catalogs the processes recorded in the journal of the sandbox
+// directory by a previous Box.
+func (b *Box) adopt() error {
+	recs, err := journal{dir: b.tempDir}.load()
+	if err != nil {
+		return err
+	}
+
+	b.catalogMu.Lock()
+	defer b.catalogMu.Unlock()
+
+	for _, rec := range recs {
+		// The address of a process is kept until the
+		// process is known to have exited.
+		if rec.Address != "" && !rec.Exited {
+			_ = b.pool.Reserve(rec.Address)
+		}
+
+		// The output of a process counts against the output
+		// budget until the process is released, even when the
+		// budget is exceeded.
+		_ = b.reserveOutput(rec.OutputLimit, true)
+
+		info, ok, err := adoptCmd(b.tempDir, b.cgroup, b.releaseTimeout, b.grace, rec, b.exited, b.released)
+		if err != nil {
+			return err
+		}
+
+		// Processes which are not adopted were released.
+		if !ok {
+			continue
+		}
+
+		b.catalog[info.id] = info
+		b.aliases[info.alias] = info.id
+
+		// Continue the aliases after the adopted processes.
+		if info.alias > b.lastAlias {
+			b.lastAlias = info.alias
+		}
+	}
+
+	return nil
 }
 
 // Box manages an internal collection of processes and resources.
@@ -37,14 +151,43 @@ func New(ctx context.Context, releaseTimeout time.Duration) (*Box, error) {
 type Box struct {
 	ctx            context.Context
 	tempDir        string
+	lock           *os.File
+	cgroup         string
 	helperPath     string
-	catalog        map[int]cmdInfo
+	catalog        map[string]cmdInfo
+	aliases        map[int64]string
+	lastAlias      int64
 	catalogMu      sync.RWMutex
 	releaseTimeout time.Duration
+	grace          time.Duration
+	limits         Limits
+	uidMap         []IDMap
+	gidMap         []IDMap
+	bridge         string
+	bridgeUp       bool
+	bridgeMu       sync.Mutex
+	pool           *network.Pool
+	output         OutputLimit
+	outputBudget   int64
+	outputUsed     int64
+	outputMu       sync.Mutex
+	archiveDir     string
+	retention      Retention
+	archiveMu      sync.Mutex
+	imageDir       string
+}
+
+// Rootless reports whether the sandbox runs without root privileges. The
+// processes of a rootless sandbox run in a user namespace which maps
+// their root to the user of the sandbox, and their resource limits are
+// only applied when the cgroup hierarchy is writable by the user.
+func (b *Box) Rootless() bool {
+	return os.Geteuid() != 0
 }
 
 // Cleanup will remove the sandbox temp directory
-// and all of its contents.
+// and all of its contents, along with the cgroup
+// of the sandbox.
 func (b *Box) Cleanup() {
 	var boxWg sync.WaitGroup
 	b.catalogMu.RLock()
@@ -57,7 +200,7 @@ func (b *Box) Cleanup() {
 			defer boxWg.Done()
 			for {
 				select {
-				case info.stop <- struct{}{}:
+				case info.stop <- b.grace:
 				case s, ok := <-info.status:
 					if !ok || s.Exited {
 						return
@@ -72,27 +215,112 @@ func (b *Box) Cleanup() {
 
 	// Cleanup the temp directory
 	os.RemoveAll(b.tempDir)
+	_ = b.lock.Close()
+
+	// Cleanup the cgroups of the processes which were
+	// not released yet along with their parent.
+	cgroups.Remove(b.cgroup, "")
 }
 
-// Start executes the commands in the sandbox environment.
-func (b *Box) Start(cmd string, args ...string) (id int, err error) {
+// Start executes the commands in the sandbox environment with the default
+// resource limits, see StartWith.
+//
+// The returned id is a UUID which is unique across sandboxes. The
+// process can also be referenced by its numeric alias, see Alias.
+func (b *Box) Start(cmd string, args ...string) (id string, err error) {
+	return b.StartWith(cmd, args)
+}
+
+// StartWith executes the commands in the sandbox environment. The resource
+// limits of the process default to the profile embedded in the library
+// and can be overridden per process using the supplied options.
+func (b *Box) StartWith(cmd string, args []string, opts ...Option) (id string, err error) {
+	o := options{
+		limits: b.limits,
+		uidMap: b.uidMap,
+		gidMap: b.gidMap,
+	}
+	for _, opt := range opts {
+		err = opt(&o)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = o.limits.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	if o.rootfs != "" {
+		o.rootfs, err = b.image(o.rootfs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if o.output.Bytes == 0 {
+		o.output = b.output
+	}
+
+	err = b.reserveOutput(o.output.Bytes, false)
+	if err != nil {
+		return "", err
+	}
+
+	// Bridged processes are attached to the bridge of the Box
+	// with an address of its pool.
+	if o.net.Mode == NetworkBridged {
+		err = b.setupBridge()
+		if err == nil {
+			o.net.Bridge = b.bridge
+			o.net.Gateway = b.pool.Gateway()
+			o.net.Address, err = b.pool.Allocate()
+		}
+
+		if err != nil {
+			b.releaseOutput(o.output.Bytes)
+			return "", err
+		}
+	}
+
+	id, alias, err := b.reserve()
+	if err != nil {
+		b.pool.Release(o.net.Address)
+		b.releaseOutput(o.output.Bytes)
+		return "", err
+	}
+
 	// Create and execute the command passing in
 	// the context, temp directory, and the helper binary path.
 	info, err := createCmd(
 		b.tempDir,
+		b.cgroup,
 		b.helperPath,
 		b.releaseTimeout,
-		cmd,
-		args...,
+		b.grace,
+		o,
+		record{
+			ID:      id,
+			Alias:   alias,
+			Command: cmd,
+			Args:    args,
+		},
+		b.exited,
+		b.released,
 	)
 	if err != nil {
-		return 0, err
+		b.pool.Release(o.net.Address)
+		b.releaseOutput(o.output.Bytes)
+		return "", err
 	}
 
 	// Lock and catalog the process
 	b.catalogMu.Lock()
 	defer b.catalogMu.Unlock()
 
+	b.aliases[info.alias] = info.id
+
 	// Add the new process cmdInfo to the catalog
 	// of running processes.
 	b.catalog[info.id] = info
@@ -100,9 +328,153 @@ func (b *Box) Start(cmd string, args ...string) (id int, err error) {
 	return info.id, nil
 }
 
+// setupBridge sets up the bridge of the Box on the host, once.
+func (b *Box) setupBridge() error {
+	b.bridgeMu.Lock()
+	defer b.bridgeMu.Unlock()
+
+	if b.bridgeUp {
+		return nil
+	}
+
+	err := network.SetupBridge(b.bridge, b.pool.Gateway())
+	if err != nil {
+		return err
+	}
+
+	b.bridgeUp = true
+	return nil
+}
+
+// exited releases the resources of the Box held by the process of the
+// record once the process has exited.
+func (b *Box) exited(rec record) {
+	if rec.Address != "" {
+		b.pool.Release(rec.Address)
+	}
+}
+
+// released archives the output of the process of the record, when the
+// Box has an archive, and releases the resources of the Box held by the
+// process once the process is released. The cgroup of a process which
+// exited is removed, since its usage can no longer be read.
+func (b *Box) released(rec record) {
+	if b.archiveDir != "" && rec.Exited {
+		// NOTE: I am purposely ignoring this error, the
+		// process is released whether or not its output
+		// could be archived.
+		_ = b.archive(rec)
+	}
+
+	if rec.Exited {
+		cgroups.Remove(b.cgroup, rec.ID)
+	}
+
+	b.releaseOutput(rec.OutputLimit)
+}
+
+// image returns the path of the image `name` of the image directory of the
+// Box. Images resolving outside of the directory, e.g. through a symlink,
+// are rejected. The errors do not hold the reason an image is rejected so
+// the filesystem of the host cannot be probed through the names.
+func (b *Box) image(name string) (string, error) {
+	if b.imageDir == "" {
+		return "", fmt.Errorf("%w: no image directory", ErrInvalidRootFS)
+	}
+
+	path, err := filepath.EvalSymlinks(filepath.Join(b.imageDir, name))
+	if err != nil || !strings.HasPrefix(path, b.imageDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRootFS, name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || (!info.IsDir() && !info.Mode().IsRegular()) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRootFS, name)
+	}
+
+	return path, nil
+}
+
+// reserveOutput reserves `bytes` of the output budget of the Box for the
+// output of a process, unless the Box has no output budget. The output is
+// reserved even when it exceeds the budget when `force` is set.
+func (b *Box) reserveOutput(bytes int64, force bool) error {
+	if b.outputBudget == 0 {
+		return nil
+	}
+
+	b.outputMu.Lock()
+	defer b.outputMu.Unlock()
+
+	if !force && b.outputUsed+bytes > b.outputBudget {
+		return fmt.Errorf(
+			"%w: %d of %d bytes are reserved",
+			ErrOutputBudget,
+			b.outputUsed,
+			b.outputBudget,
+		)
+	}
+
+	b.outputUsed += bytes
+	return nil
+}
+
+// releaseOutput returns `bytes` reserved by reserveOutput to the output
+// budget of the Box.
+func (b *Box) releaseOutput(bytes int64) {
+	if b.outputBudget == 0 {
+		return
+	}
+
+	b.outputMu.Lock()
+	defer b.outputMu.Unlock()
+
+	b.outputUsed -= bytes
+}
+
+// reserve generates an id which is not used by any process in the
+// catalog and assigns the next numeric alias.
+func (b *Box) reserve() (string, int64, error) {
+	b.catalogMu.Lock()
+	defer b.catalogMu.Unlock()
+
+	for {
+		id, err := newID()
+		if err != nil {
+			return "", 0, err
+		}
+
+		if _, ok := b.catalog[id]; !ok {
+			b.lastAlias++
+			return id, b.lastAlias, nil
+		}
+	}
+}
+
+// Alias returns the numeric alias of the process for the given id. The
+// alias is a compatibility path for clients which identify processes
+// using integers. It is unique within the Box and every method which
+// accepts an id also accepts the alias in its decimal form.
+func (b *Box) Alias(id string) (int64, error) {
+	info, err := b.getInfo(id)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.alias, nil
+}
+
 // Stop will cancel the child context used to call the helper binary, the helper
-// binary will monitor for sigterm and will cancel the subprocess context.
-func (b *Box) Stop(id int) error {
+// binary will monitor for sigterm and will cancel the subprocess context. The
+// processes which did not exit after the grace period of the Box are killed,
+// see WithGracePeriod.
+func (b *Box) Stop(id string) error {
+	return b.StopWithGrace(id, b.grace)
+}
+
+// StopWithGrace stops the process like Stop, killing every process of the
+// process which did not exit after the grace period `grace`.
+func (b *Box) StopWithGrace(id string, grace time.Duration) error {
 	// Load the process info from the catalog
 	info, err := b.getInfo(id)
 	if err != nil {
@@ -125,23 +497,219 @@ func (b *Box) Stop(id int) error {
 			// is not constantly taking the `<-info.stop` case
 			// statement since the `select` statement is
 			// stochastic in its execution.
-			info.stop <- struct{}{}
+			select {
+			case <-b.ctx.Done():
+				return b.ctx.Err()
+			case <-info.done:
+				b.rmProc(id)
+				return ErrProcessNotFound
+			case info.stop <- grace:
+			}
 		}
 	}
 
 	return nil
 }
 
+// ErrProcessExited is returned by Pause and Resume when the process
+// already exited.
+var ErrProcessExited = errors.New("process exited")
+
+// ErrNoFreezer is returned by Pause and Resume when the process has no
+// cgroup to freeze, e.g. a rootless sandbox without a writable cgroup
+// hierarchy.
+var ErrNoFreezer = cgroups.ErrNoCGroup
+
+// Pause suspends every process of the process with the given id by
+// freezing its cgroup. The processes keep their state and continue
+// where they left off once the process is resumed using Resume.
+// Stopping a paused process resumes it so it can handle the signal.
+func (b *Box) Pause(id string) error {
+	return b.setPaused(id, true)
+}
+
+// Resume continues the process with the given id paused using Pause.
+func (b *Box) Resume(id string) error {
+	return b.setPaused(id, false)
+}
+
+// ErrProcessNotStarted is returned by Signal when the process has not
+// been started by the helper yet.
+var ErrProcessNotStarted = errors.New("process has not started")
+
+// Signal sends the signal to the main process of the process with the
+// given id. Signals are delivered once a paused process is resumed.
+func (b *Box) Signal(id string, sig syscall.Signal) error {
+	return b.signal(id, sig, false)
+}
+
+// SignalGroup sends the signal to every process of the process group of
+// the process with the given id, which is led by the main process.
+// Processes which left the process group, e.g. daemons, are not
+// signaled.
+func (b *Box) SignalGroup(id string, sig syscall.Signal) error {
+	return b.signal(id, sig, true)
+}
+
+// signal asks the tracker of the process with the given id to send the
+// signal to the process or its process group.
+func (b *Box) signal(id string, sig syscall.Signal, group bool) error {
+	if !validSignal(sig) {
+		return fmt.Errorf("%w: %d", ErrInvalidSignal, sig)
+	}
+
+	// Load the process info from the catalog
+	info, err := b.getInfo(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case _, ok := <-info.status:
+		if !ok {
+			b.rmProc(id)
+			return ErrProcessNotFound
+		}
+	}
+
+	result := make(chan error, 1)
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case <-info.done:
+		b.rmProc(id)
+		return ErrProcessNotFound
+	case info.signal <- signalRequest{sig: sig, group: group, err: result}:
+	}
+
+	return b.result(id, info, result)
+}
+
+// setPaused asks the tracker of the process with the given id to pause
+// or resume the process.
+func (b *Box) setPaused(id string, paused bool) error {
+	// Load the process info from the catalog
+	info, err := b.getInfo(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case _, ok := <-info.status:
+		if !ok {
+			b.rmProc(id)
+			return ErrProcessNotFound
+		}
+	}
+
+	result := make(chan error, 1)
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case <-info.done:
+		b.rmProc(id)
+		return ErrProcessNotFound
+	case info.pause <- pauseRequest{paused: paused, err: result}:
+	}
+
+	return b.result(id, info, result)
+}
+
+// result waits for the result of a request sent to the tracker of the
+// process with the given id. The result of a request the tracker served
+// before it stopped is returned even once the tracker stopped.
+func (b *Box) result(id string, info cmdInfo, result <-chan error) error {
+	select {
+	case err := <-result:
+		return err
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case <-info.done:
+	}
+
+	select {
+	case err := <-result:
+		return err
+	default:
+		b.rmProc(id)
+		return ErrProcessNotFound
+	}
+}
+
+// Reason is the reason a process terminated.
+type Reason string
+
+const (
+	// ReasonExited is the reason of a process which exited on its own.
+	ReasonExited Reason = "exited"
+
+	// ReasonSignaled is the reason of a process which was terminated by
+	// a signal it did not handle. The signal is included in the Status.
+	ReasonSignaled Reason = "signaled"
+
+	// ReasonOOMKilled is the reason of a process which was killed by the
+	// OOM killer because it exceeded its memory limit.
+	ReasonOOMKilled Reason = "oom_killed"
+
+	// ReasonStopped is the reason of a process which was stopped using
+	// Stop.
+	ReasonStopped Reason = "stopped"
+
+	// ReasonTimedOut is the reason of a process which was stopped because
+	// it exceeded its deadline.
+	ReasonTimedOut Reason = "timed_out"
+
+	// ReasonFailed is the reason of a process whose helper failed to set
+	// up or start the process, e.g. the command does not exist. The error
+	// of the helper is included in the Status.
+	ReasonFailed Reason = "failed"
+)
+
 // Status indicates the current status of the process and if
-// the process has exited the exit code will be included
+// the process has exited the exit code and the reason it
+// terminated will be included
 type Status struct {
-	Command string
-	Exited  bool
-	Code    int
+	// Command and Args are the command line of the process and
+	// Identity is the identity of its requester, see WithIdentity.
+	Command  string
+	Args     []string
+	Identity string
+
+	// PID is the host PID of the helper of the process and JobPID
+	// is the PID of the process inside its PID namespace. JobPID
+	// is zero until the process is observed running.
+	PID    int
+	JobPID int
+
+	// Started and Finished are the times the process started and
+	// finished, and Duration is the wall time it has run for.
+	// Deadline is the time the process is stopped at, if it was
+	// started with WithDeadline.
+	Started  time.Time
+	Finished time.Time
+	Duration time.Duration
+	Deadline time.Time
+
+	// Paused reports that the process is paused, see Pause.
+	Paused bool
+
+	// Truncated reports that output of the process was discarded
+	// because it reached its output limit, see WithOutputLimit.
+	Truncated bool
+
+	Exited bool
+	Code   int
+	Reason Reason
+	Signal syscall.Signal
+	Error  string
 }
 
 // Stat returns the status of the process with the given id.
-func (b *Box) Stat(id int) (Status, error) {
+func (b *Box) Stat(id string) (Status, error) {
 	// Load the process info from the catalog
 	info, err := b.getInfo(id)
 	if err != nil {
@@ -162,9 +730,171 @@ func (b *Box) Stat(id int) (Status, error) {
 	}
 }
 
-// Output returns a io.ReadCloser instance for reading the
-// output of the process for the given id.
-func (b *Box) Output(id int) (io.ReadCloser, error) {
+// State selects the processes listed by List by whether they exited.
+type State string
+
+const (
+	// StateAny lists all processes.
+	StateAny State = ""
+
+	// StateRunning lists the processes which have not exited.
+	StateRunning State = "running"
+
+	// StateExited lists the processes which exited and were not
+	// released yet.
+	StateExited State = "exited"
+)
+
+// Filter selects the processes listed by List. Fields which are not set
+// match every process.
+type Filter struct {
+	State   State
+	Command string
+
+	// Owner matches the identity of the requester of the process,
+	// see WithIdentity.
+	Owner string
+}
+
+// matches reports whether the process with the status `s` is selected
+// by the filter.
+func (f Filter) matches(s Status) bool {
+	switch {
+	case f.State == StateRunning && s.Exited,
+		f.State == StateExited && !s.Exited,
+		f.Command != "" && f.Command != s.Command,
+		f.Owner != "" && f.Owner != s.Identity:
+		return false
+	}
+
+	return true
+}
+
+// Summary is the summary of a process returned by List.
+type Summary struct {
+	ID    string
+	Alias int64
+	Status
+}
+
+// List returns the summaries of the processes of the sandbox selected by
+// `filter`, ordered by their alias. Released processes are not listed.
+func (b *Box) List(filter Filter) ([]Summary, error) {
+	b.catalogMu.RLock()
+	infos := make([]cmdInfo, 0, len(b.catalog))
+	for _, info := range b.catalog {
+		infos = append(infos, info)
+	}
+	b.catalogMu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].alias < infos[j].alias
+	})
+
+	var summaries []Summary
+	for _, info := range infos {
+		status, err := b.Stat(info.id)
+		if errors.Is(err, ErrProcessNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.matches(status) {
+			summaries = append(summaries, Summary{
+				ID:     info.id,
+				Alias:  info.alias,
+				Status: status,
+			})
+		}
+	}
+
+	return summaries, nil
+}
+
+// EventType is the type of a status transition of a process.
+type EventType string
+
+const (
+	// EventStarted is the transition of a process which started. It is
+	// the first event sent to every watcher.
+	EventStarted EventType = "started"
+
+	// EventExited is the transition of a process which exited.
+	EventExited EventType = "exited"
+
+	// EventReleased is the transition of a process whose resources were
+	// released after its release timeout. It is the last event sent to
+	// every watcher and the process is no longer available afterwards.
+	EventReleased EventType = "released"
+)
+
+// eventTypes is the number of transitions of a process. Watchers are
+// buffered for all of them so a watcher never blocks the process.
+const eventTypes = 3
+
+// Event is a status transition of a process with the status of the
+// process at the transition.
+type Event struct {
+	Type   EventType
+	Status Status
+}
+
+// Watch returns a channel of the status transitions of the process with
+// the given id. The transitions which already happened are sent first,
+// and the channel is closed after the process is released. The channel
+// is buffered for every transition so it does not need to be drained.
+func (b *Box) Watch(id string) (<-chan Event, error) {
+	info, err := b.getInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-b.ctx.Done():
+		return nil, b.ctx.Err()
+	case events, ok := <-info.watch:
+		if !ok {
+			b.rmProc(id)
+			return nil, ErrProcessNotFound
+		}
+
+		return events, nil
+	}
+}
+
+// Usage is the resource usage of a process, see Box.Usage.
+type Usage = cgroups.Usage
+
+// ErrNoUsage is returned by Usage when the resource usage of the process is
+// not accounted, e.g. a rootless sandbox without a writable cgroup
+// hierarchy.
+var ErrNoUsage = cgroups.ErrNoUsage
+
+// Usage returns the resource usage of the process for the given id read
+// from the cgroup of the process. The usage of a process which exited is
+// the usage at the time it exited.
+func (b *Box) Usage(id string) (Usage, error) {
+	// Load the process info from the catalog
+	info, err := b.getInfo(id)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return cgroups.ReadUsage(b.cgroup, info.id)
+}
+
+// Output returns an OutputReader instance for reading the
+// selected output streams of the process for the given id.
+// By default the output is read from the start and followed
+// until the process exits, see OutputOption.
+func (b *Box) Output(id string, streams Stream, opts ...OutputOption) (*OutputReader, error) {
+	if streams == 0 || streams&^Combined != 0 {
+		return nil, ErrInvalidStream
+	}
+
 	// Load the process info from the catalog
 	info, err := b.getInfo(id)
 	if err != nil {
@@ -181,33 +911,132 @@ func (b *Box) Output(id int) (io.ReadCloser, error) {
 			return nil, ErrProcessNotFound
 		}
 
-		return output, nil
+		out := newOutputReader(output, streams)
+		err = out.apply(opts...)
+		if err != nil {
+			out.Close()
+			return nil, err
+		}
+
+		return out, nil
+	}
+}
+
+// Input returns an io.WriteCloser instance for writing to the
+// stdin of the process for the given id. Closing the writer
+// closes the stdin of the process, after which no further
+// input can be written.
+func (b *Box) Input(id string) (io.WriteCloser, error) {
+	// Load the process info from the catalog
+	info, err := b.getInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pull the input io.WriteCloser from the process info
+	select {
+	case <-b.ctx.Done():
+		return nil, b.ctx.Err()
+	case input, ok := <-info.input:
+		if !ok {
+			b.rmProc(id)
+			return nil, ErrProcessNotFound
+		}
+
+		// The stdin of processes adopted from a previous
+		// Box is not available.
+		if input == nil {
+			return nil, ErrNoInput
+		}
+
+		return input, nil
+	}
+}
+
+// Resize sets the size of the terminal of the process for the given id.
+// Only processes started using WithTTY have a terminal.
+func (b *Box) Resize(id string, rows, cols uint16) error {
+	// Load the process info from the catalog
+	info, err := b.getInfo(id)
+	if err != nil {
+		return err
+	}
+
+	if !info.tty {
+		return ErrNoTerminal
+	}
+
+	// Pull the control io.Writer from the process info
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case control, ok := <-info.control:
+		if !ok {
+			b.rmProc(id)
+			return ErrProcessNotFound
+		}
+
+		return pty.WriteSize(control, pty.Size{Rows: rows, Cols: cols})
 	}
 }
 
 var ErrProcessNotFound = errors.New("process not found")
 
+// ErrNoInput is returned by Input when the stdin of the process is not
+// available because the process was adopted from a previous Box.
+var ErrNoInput = errors.New("process input is not available")
+
+// ErrNoTerminal is returned by Resize when the process was not started
+// with a terminal.
+var ErrNoTerminal = errors.New("process has no terminal")
+
+// ErrInvalidStream is returned by Output when the stream selection does
+// not contain Stdout, Stderr or both.
+var ErrInvalidStream = errors.New("invalid output stream")
+
 // rmProc removes the process with the given id from the catalog.
-func (b *Box) rmProc(id int) {
+func (b *Box) rmProc(id string) {
 	// Lock the catalog
 	b.catalogMu.Lock()
 	defer b.catalogMu.Unlock()
 
+	info, ok := b.lookup(id)
+	if !ok {
+		return
+	}
+
 	// Remove the process from the catalog
-	delete(b.catalog, id)
+	delete(b.catalog, info.id)
+	delete(b.aliases, info.alias)
 }
 
 // getInfo will return the cmdInfo for the given id.
 // This method is split out to allow for testing as well
 // as minimizing the total lock time.
-func (b *Box) getInfo(id int) (cmdInfo, error) {
+func (b *Box) getInfo(id string) (cmdInfo, error) {
 	b.catalogMu.RLock()
 	defer b.catalogMu.RUnlock()
 
-	i, ok := b.catalog[id]
+	i, ok := b.lookup(id)
 	if !ok {
 		return cmdInfo{}, ErrProcessNotFound
 	}
 
 	return i, nil
 }
+
+// lookup finds the process by its id or, failing that, by its numeric
+// alias. The caller must hold the catalog lock.
+func (b *Box) lookup(id string) (cmdInfo, bool) {
+	if i, ok := b.catalog[id]; ok {
+		return i, true
+	}
+
+	alias, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return cmdInfo{}, false
+	}
+
+	i, ok := b.catalog[b.aliases[alias]]
+	return i, ok
+}
@@ -1,10 +1,21 @@
 package sandbox
 
 import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
 	"context"
 	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -45,7 +56,7 @@ func Test_Box_Start_Output(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	output, err := box.Output(id)
+	output, err := box.Output(id, Combined)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,3 +105,1934 @@ func Test_Box_Start_Output(t *testing.T) {
 		t.Fatal("expected command to be stopped")
 	}
 }
+
+func Test_Box_Output_streams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdata := map[string]struct {
+		streams  Stream
+		expected string
+	}{
+		"stdout": {
+			streams:  Stdout,
+			expected: "out\n",
+		},
+		"stderr": {
+			streams:  Stderr,
+			expected: "err\n",
+		},
+	}
+
+	for name, test := range testdata {
+		t.Run(name, func(t *testing.T) {
+			output, err := box.Output(id, test.streams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+
+	output, err := box.Output(id, Combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	streams := map[Stream]string{}
+	for {
+		chunk, err := output.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streams[chunk.Stream] += string(chunk.Data)
+	}
+
+	if streams[Stdout] != "out\n" || streams[Stderr] != "err\n" {
+		t.Fatalf("expected tagged chunks, got %v", streams)
+	}
+}
+
+func Test_Box_Input(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := box.Input(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = input.Write([]byte("hello sandbox\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the input sends EOF to cat which then exits.
+	err = input.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello sandbox\n" {
+		t.Fatalf("expected input to be echoed, got %q", data)
+	}
+}
+
+func Test_Box_TTY(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.StartWith(
+		"sh",
+		[]string{"-c", "read x; stty size"},
+		WithTTY(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = box.Resize(id, 42, 132)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The resize events and the keystrokes are relayed through
+	// separate pipes so give the helper time to apply the size.
+	time.Sleep(time.Millisecond * 100)
+
+	input, err := box.Input(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = input.Write([]byte("go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), "42 132") {
+		t.Fatalf("expected terminal size in output, got %q", data)
+	}
+
+	id, err = box.Start("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = box.Resize(id, 42, 132)
+	if err != ErrNoTerminal {
+		t.Fatalf("expected ErrNoTerminal, got %v", err)
+	}
+}
+
+func Test_Box_Alias(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	first, err := box.Start("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := box.Start("false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatalf("expected unique ids, got %q twice", first)
+	}
+
+	alias, err := box.Alias(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alias != 2 {
+		t.Fatalf("expected alias 2, got %d", alias)
+	}
+
+	// The alias can be used in place of the id.
+	for {
+		status, err := box.Stat(strconv.FormatInt(alias, 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Exited {
+			if status.Command != "false" || status.Code != 1 {
+				t.Fatalf("unexpected status %+v", status)
+			}
+
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	_, err = box.Stat("0")
+	if err != ErrProcessNotFound {
+		t.Fatalf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func Test_Box_adopt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+
+	// The first Box is abandoned without cleanup to simulate a restart.
+	firstCtx, firstCancel := context.WithCancel(ctx)
+	defer firstCancel()
+
+	first, err := New(firstCtx, time.Minute*5, WithStateDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finished, err := first.Start("echo", "early")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := first.Start("sh", "-c", "sleep 0.5; echo late")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		status, err := first.Stat(finished)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Exited {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// The directory is locked until the first Box is done.
+	_, err = New(ctx, time.Minute*5, WithStateDir(dir))
+	if !errors.Is(err, ErrStateDirLocked) {
+		t.Fatalf("expected ErrStateDirLocked, got %v", err)
+	}
+
+	firstCancel()
+
+	var box *Box
+	for i := 0; i < 50; i++ {
+		box, err = New(ctx, time.Minute*5, WithStateDir(dir))
+		if !errors.Is(err, ErrStateDirLocked) {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	tests := map[string]struct {
+		id       string
+		command  string
+		expected string
+	}{
+		"finished": {finished, "echo", "early\n"},
+		"running":  {running, "sh", "late\n"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			output, err := box.Output(test.id, Stdout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, data)
+			}
+
+			status, err := box.Stat(test.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !status.Exited || status.Code != 0 || status.Command != test.command ||
+				status.Reason != ReasonExited {
+				t.Fatalf("unexpected status %+v", status)
+			}
+		})
+	}
+
+	_, err = box.Input(running)
+	if err != ErrNoInput {
+		t.Fatalf("expected ErrNoInput, got %v", err)
+	}
+
+	// Aliases continue after the adopted processes.
+	id, err := box.Start("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alias, err := box.Alias(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alias != 3 {
+		t.Fatalf("expected alias 3, got %d", alias)
+	}
+}
+
+func Test_Box_RootFS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	images := t.TempDir()
+	box, err := New(ctx, time.Minute*5, WithImageDir(images))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	// Build a root filesystem containing only a static binary.
+	root := filepath.Join(images, "fscheck")
+	build := exec.Command(
+		"go", "build",
+		"-o", filepath.Join(root, "bin", "fscheck"),
+		"./testdata/fscheck",
+	)
+	build.Env = append(os.Environ(), "CGO_ENABLED=0")
+	out, err := build.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build fscheck: %s: %s", err, out)
+	}
+
+	secret := filepath.Join(t.TempDir(), "secret")
+	err = os.WriteFile(secret, []byte("secret"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tarDir(root, filepath.Join(images, "fscheck.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		secret + " denied",
+		"/proc/1/status ok",
+		"/dev/null ok",
+		"write:/tmp/file ok",
+		"write:/bin/file denied",
+		"",
+	}, "\n")
+
+	tests := map[string]string{
+		"directory": "fscheck",
+		"tarball":   "fscheck.tar.gz",
+	}
+
+	for name, rootfs := range tests {
+		rootfs := rootfs
+		t.Run(name, func(t *testing.T) {
+			id, err := box.StartWith(
+				"/bin/fscheck",
+				[]string{
+					secret,
+					"/proc/1/status",
+					"/dev/null",
+					"write:/tmp/file",
+					"write:/bin/file",
+				},
+				WithRootFS(rootfs),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := box.Output(id, Combined)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != expected {
+				t.Fatalf("expected %q, got %q", expected, data)
+			}
+		})
+	}
+
+	// Images are only resolved within the image directory.
+	err = os.Symlink(t.TempDir(), filepath.Join(images, "escape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"missing", root, "../fscheck", "fscheck/../fscheck", ".", "escape", ""} {
+		_, err = box.StartWith("/bin/fscheck", nil, WithRootFS(name))
+		if !errors.Is(err, ErrInvalidRootFS) {
+			t.Fatalf("expected ErrInvalidRootFS for %q, got %v", name, err)
+		}
+	}
+
+	// A Box without an image directory has no images.
+	plain, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Cleanup()
+
+	_, err = plain.StartWith("/bin/fscheck", nil, WithRootFS("fscheck"))
+	if !errors.Is(err, ErrInvalidRootFS) {
+		t.Fatalf("expected ErrInvalidRootFS without an image directory, got %v", err)
+	}
+
+	// Image directories which other users may write to are rejected.
+	err = os.Chmod(images, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(ctx, time.Minute*5, WithImageDir(images))
+	if !errors.Is(err, ErrInvalidImageDir) {
+		t.Fatalf("expected ErrInvalidImageDir, got %v", err)
+	}
+}
+
+// tarDir writes the contents of the directory `dir` to a gzip compressed
+// tarball at `path`.
+func Test_Box_IDMaps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	if box.Rootless() {
+		t.Skip("the default ID maps of a rootless sandbox map a single ID")
+	}
+
+	tests := map[string]struct {
+		opts     []Option
+		expected []string
+	}{
+		"default": {
+			expected: []string{"0", "100000", "65536"},
+		},
+		"custom": {
+			opts: []Option{WithIDMaps(
+				[]IDMap{{ContainerID: 0, HostID: 200000, Size: 1000}},
+				[]IDMap{{ContainerID: 0, HostID: 300000, Size: 1000}},
+			)},
+			expected: []string{"0", "200000", "1000"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			id, err := box.StartWith("cat", []string{"/proc/self/uid_map"}, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := box.Output(id, Combined)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fields := strings.Fields(string(data))
+			if strings.Join(fields, " ") != strings.Join(test.expected, " ") {
+				t.Fatalf("expected uid_map %v, got %q", test.expected, data)
+			}
+		})
+	}
+
+	_, err = box.StartWith("cat", nil, WithIDMaps(
+		[]IDMap{{ContainerID: 1, HostID: 200000, Size: 1000}},
+		[]IDMap{{ContainerID: 0, HostID: 200000, Size: 1000}},
+	))
+	if !errors.Is(err, ErrInvalidIDMap) {
+		t.Fatalf("expected ErrInvalidIDMap, got %v", err)
+	}
+}
+
+func Test_Box_Network(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	tests := map[string]struct {
+		mode     Network
+		bridged  bool
+		args     []string
+		expected string
+	}{
+		"none": {
+			mode:     NetworkNone,
+			args:     []string{"-o", "link", "show", "lo"},
+			expected: "<LOOPBACK>",
+		},
+		"loopback": {
+			mode:     NetworkLoopback,
+			args:     []string{"-o", "link", "show", "lo"},
+			expected: "<LOOPBACK,UP,LOWER_UP>",
+		},
+		"bridged": {
+			mode:     NetworkBridged,
+			bridged:  true,
+			args:     []string{"route", "show", "default"},
+			expected: "default via 172.29.0.1 dev eth0",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if _, err := exec.LookPath("iptables"); test.bridged && err != nil {
+				t.Skip("iptables is required for bridged processes")
+			}
+
+			id, err := box.StartWith("ip", test.args, WithNetwork(test.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := box.Output(id, Combined)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(data), test.expected) {
+				t.Fatalf("expected output containing %q, got %q", test.expected, data)
+			}
+		})
+	}
+
+	_, err = box.StartWith("ip", nil, WithNetwork("wifi"))
+	if !errors.Is(err, ErrInvalidNetwork) {
+		t.Fatalf("expected ErrInvalidNetwork, got %v", err)
+	}
+}
+
+func Test_Box_Network_isolation(t *testing.T) {
+	for _, tool := range []string{"iptables", "ping"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is required for the isolation of bridged processes", tool)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5, WithBridge("sandboxiso0", "172.30.0.0/24"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	// The first process of the pool gets the first address after the
+	// gateway.
+	id, err := box.StartWith("sleep", []string{"10"}, WithNetwork(NetworkBridged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = box.Stop(id) }()
+
+	tests := map[string]struct {
+		address string
+		code    int
+	}{
+		"loopback": {"127.0.0.1", 0},
+		"gateway":  {"172.30.0.1", 1},
+		"job":      {"172.30.0.2", 1},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			id, err := box.StartWith(
+				"ping",
+				[]string{"-c", "1", "-W", "1", test.address},
+				WithNetwork(NetworkBridged),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var status Status
+			for !status.Exited {
+				status, err = box.Stat(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				time.Sleep(time.Millisecond * 10)
+			}
+
+			if status.Code != test.code {
+				t.Fatalf("expected ping of %s to exit with %d, got %+v", test.address, test.code, status)
+			}
+		})
+	}
+}
+
+func Test_Box_Usage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sleep", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = box.Stop(id) }()
+
+	// The cgroup of the process is created by the helper after
+	// Start returns.
+	var usage Usage
+	for i := 0; i < 50; i++ {
+		usage, err = box.Usage(id)
+		if err == nil && usage.Pids > 0 {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	if errors.Is(err, ErrNoUsage) && box.Rootless() {
+		t.Skip("the cgroup hierarchy is not writable")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The helper, the isolated helper and the command.
+	if usage.Pids < 1 {
+		t.Fatalf("expected the processes to be accounted, got %+v", usage)
+	}
+
+	if usage.Memory <= 0 || usage.MemoryPeak < usage.Memory {
+		t.Fatalf("expected the memory to be accounted, got %+v", usage)
+	}
+
+	_, err = box.Usage("missing")
+	if !errors.Is(err, ErrProcessNotFound) {
+		t.Fatalf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func Test_Box_Usage_released(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Millisecond*50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	if box.Rootless() {
+		t.Skip("the cgroup hierarchy is not writable")
+	}
+
+	id, err := box.Start("sleep", "0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var usage error
+	for event := range events {
+		if event.Type == EventExited {
+			_, usage = box.Usage(id)
+		}
+	}
+
+	if errors.Is(usage, ErrNoUsage) {
+		t.Skip("the cgroup of the process was not created")
+	}
+
+	// The cgroups of the process are removed once it is released, and
+	// the parent cgroup once the Box is cleaned up.
+	parent := box.cgroup
+	for _, folder := range []string{"memory", "pids", "freezer"} {
+		_, err = os.Stat(filepath.Join("/sys/fs/cgroup", folder, parent, id))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected the %s cgroup of the process to be removed, got %v", folder, err)
+		}
+	}
+
+	box.Cleanup()
+	for _, folder := range []string{"memory", "pids", "freezer"} {
+		_, err = os.Stat(filepath.Join("/sys/fs/cgroup", folder, parent))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("expected the %s parent cgroup to be removed, got %v", folder, err)
+		}
+	}
+}
+
+func Test_Box_Termination(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	tests := map[string]struct {
+		command string
+		args    []string
+		stop    bool
+		code    int
+		reason  Reason
+		signal  syscall.Signal
+	}{
+		"exited":   {"sh", []string{"-c", "exit 3"}, false, 3, ReasonExited, 0},
+		"signaled": {"sh", []string{"-c", "kill -9 $$"}, false, 137, ReasonSignaled, syscall.SIGKILL},
+		"stopped":  {"sleep", []string{"10"}, true, 143, ReasonStopped, syscall.SIGTERM},
+		"failed":   {"/does/not/exist", nil, false, 2, ReasonFailed, 0},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			id, err := box.Start(test.command, test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.stop {
+				// Give the helper time to start the command.
+				time.Sleep(time.Millisecond * 200)
+
+				err = box.Stop(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var status Status
+			for !status.Exited {
+				status, err = box.Stat(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				time.Sleep(time.Millisecond * 10)
+			}
+
+			if status.Code != test.code || status.Reason != test.reason || status.Signal != test.signal {
+				t.Fatalf("unexpected status %+v", status)
+			}
+
+			if (test.reason == ReasonFailed) != (status.Error != "") {
+				t.Fatalf("unexpected error %q for reason %s", status.Error, status.Reason)
+			}
+		})
+	}
+}
+
+func Test_Box_Deadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	_, err = box.StartWith("sleep", []string{"10"}, WithDeadline(0))
+	if !errors.Is(err, ErrInvalidDeadline) {
+		t.Fatalf("expected ErrInvalidDeadline, got %v", err)
+	}
+
+	id, err := box.StartWith("sleep", []string{"10"}, WithDeadline(time.Millisecond*300))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var event Event
+	for event.Type != EventExited {
+		select {
+		case event = <-events:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected the process to be stopped at its deadline")
+		}
+	}
+
+	status := event.Status
+	if status.Reason != ReasonTimedOut || status.Signal != syscall.SIGTERM {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	if status.Deadline.Sub(status.Started) != time.Millisecond*300 || status.Finished.Before(status.Deadline) {
+		t.Fatalf("unexpected deadline of status %+v", status)
+	}
+}
+
+func Test_Box_StopWithGrace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := New(ctx, time.Minute*5, WithGracePeriod(-time.Second))
+	if !errors.Is(err, ErrInvalidGracePeriod) {
+		t.Fatalf("expected ErrInvalidGracePeriod, got %v", err)
+	}
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	// The process ignores SIGTERM and leaves an orphan behind.
+	id, err := box.Start("sh", "-c", "trap '' TERM; (sleep 30 &); echo started; sleep 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	_, err = io.ReadFull(output, make([]byte, len("started\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = box.StopWithGrace(id, time.Millisecond*200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var event Event
+	for event.Type != EventExited {
+		select {
+		case event = <-events:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected the process to be killed after the grace period")
+		}
+	}
+
+	status := event.Status
+	if status.Reason != ReasonStopped || status.Signal != syscall.SIGKILL {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func Test_Box_Pause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "while true; do echo tick; sleep 0.02; done")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	var read int64
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := output.Read(buf)
+			atomic.AddInt64(&read, int64(n))
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// grows waits for the output to grow past `n` bytes.
+	grows := func(n int64) bool {
+		for start := time.Now(); time.Since(start) < time.Second*5; {
+			if atomic.LoadInt64(&read) > n {
+				return true
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+
+		return false
+	}
+
+	if !grows(0) {
+		t.Fatal("expected the process to write output")
+	}
+
+	err = box.Pause(id)
+	if errors.Is(err, ErrNoFreezer) && box.Rootless() {
+		t.Skip("the cgroup freezer is not available to a rootless sandbox")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := box.Stat(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Paused || status.Exited {
+		t.Fatalf("expected the process to be paused, got %+v", status)
+	}
+
+	// Output written before the process was frozen may still be
+	// in flight.
+	time.Sleep(time.Millisecond * 100)
+	paused := atomic.LoadInt64(&read)
+
+	time.Sleep(time.Millisecond * 300)
+	if atomic.LoadInt64(&read) != paused {
+		t.Fatal("expected the paused process not to write output")
+	}
+
+	err = box.Resume(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !grows(paused) {
+		t.Fatal("expected the resumed process to write output")
+	}
+
+	status, err = box.Stat(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Paused {
+		t.Fatalf("expected the process to be resumed, got %+v", status)
+	}
+
+	// A paused process is resumed to be stopped.
+	err = box.Pause(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = box.Stop(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var event Event
+	for event.Type != EventExited {
+		select {
+		case event = <-events:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected the paused process to be stopped")
+		}
+	}
+
+	if event.Status.Paused || event.Status.Reason != ReasonStopped {
+		t.Fatalf("unexpected status %+v", event.Status)
+	}
+
+	err = box.Pause(id)
+	if !errors.Is(err, ErrProcessExited) {
+		t.Fatalf("expected ErrProcessExited, got %v", err)
+	}
+}
+
+func Test_Box_Signal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "trap 'echo hup' HUP; echo ready; while true; do sleep 0.02; done")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	_, err = io.ReadFull(output, make([]byte, len("ready\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = box.Signal(id, syscall.Signal(0))
+	if !errors.Is(err, ErrInvalidSignal) {
+		t.Fatalf("expected ErrInvalidSignal, got %v", err)
+	}
+
+	err = box.Signal(id, syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hup := make([]byte, len("hup\n"))
+	_, err = io.ReadFull(output, hup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(hup) != "hup\n" {
+		t.Fatalf("expected the process to handle SIGHUP, got %q", hup)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The helpers are not part of the process group of the process so
+	// they survive to report the signal.
+	err = box.SignalGroup(id, syscall.SIGUSR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var event Event
+	for event.Type != EventExited {
+		select {
+		case event = <-events:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected the process to be terminated by the signal")
+		}
+	}
+
+	status := event.Status
+	if status.Reason != ReasonSignaled || status.Signal != syscall.SIGUSR1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	err = box.Signal(id, syscall.SIGHUP)
+	if !errors.Is(err, ErrProcessExited) {
+		t.Fatalf("expected ErrProcessExited, got %v", err)
+	}
+}
+
+func Test_Box_OutputLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := New(ctx, time.Minute*5, WithOutputBudget(100, OutputLimit{Bytes: 200}))
+	if !errors.Is(err, ErrInvalidOutputLimit) {
+		t.Fatalf("expected ErrInvalidOutputLimit, got %v", err)
+	}
+
+	box, err := New(ctx, time.Minute*5, WithOutputBudget(4096, OutputLimit{Bytes: 1024}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	script := "i=0; while [ $i -lt 500 ]; do echo line$i; i=$((i+1)); done"
+
+	tests := map[string]struct {
+		opts   []Option
+		prefix string
+		suffix string
+	}{
+		"truncate": {nil, "line0\n", TruncatedMarker},
+		"ring":     {[]Option{WithOutputLimit(OutputLimit{Bytes: 1024, Mode: OutputRing})}, "", "line499\n"},
+	}
+
+	for name, test := range tests {
+		id, err := box.StartWith("sh", []string{"-c", script}, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		events, err := box.Watch(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The output is read once the process exited, since readers
+		// keep the output they read before a ring discarded it.
+		for event := range events {
+			if event.Type == EventExited {
+				break
+			}
+		}
+
+		output, err := box.Output(id, Stdout)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := io.ReadAll(output)
+		_ = output.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out := string(data)
+		// The marker is not counted against the limit.
+		retained := len(strings.TrimSuffix(out, TruncatedMarker))
+		if !strings.HasPrefix(out, test.prefix) || !strings.HasSuffix(out, test.suffix) || retained > 1024 {
+			t.Fatalf("%s: unexpected output of %d bytes %q", name, len(out), out)
+		}
+
+		if strings.Contains(out, "line0\n") == (test.prefix == "") {
+			t.Fatalf("%s: unexpected output %q", name, out)
+		}
+
+		status, err := box.Stat(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !status.Truncated {
+			t.Fatalf("%s: expected the output to be truncated, got %+v", name, status)
+		}
+	}
+
+	// Both processes hold their limit until they are released.
+	_, err = box.StartWith("true", nil, WithOutputLimit(OutputLimit{Bytes: 4096}))
+	if !errors.Is(err, ErrOutputBudget) {
+		t.Fatalf("expected ErrOutputBudget, got %v", err)
+	}
+
+	_, err = box.StartWith("true", nil, WithOutputLimit(OutputLimit{Bytes: 2048}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Box_Output_offset(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "printf 'one\\ntwo\\nthr'; echo err >&2; echo ee; printf 'four\\nfive'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for event := range events {
+		if event.Type == EventExited {
+			break
+		}
+	}
+
+	read := func(streams Stream, opts ...OutputOption) []Chunk {
+		output, err := box.Output(id, streams, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer output.Close()
+
+		var chunks []Chunk
+		for {
+			chunk, err := output.Next()
+			if err == io.EOF {
+				return chunks
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(chunk.Data) > 0 {
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+
+	join := func(chunks []Chunk) string {
+		var data []byte
+		for _, chunk := range chunks {
+			data = append(data, chunk.Data...)
+		}
+
+		return string(data)
+	}
+
+	tails := map[int]string{
+		0: "",
+		1: "five",
+		3: "three\nfour\nfive",
+		9: "one\ntwo\nthree\nfour\nfive",
+	}
+
+	for lines, expected := range tails {
+		if got := join(read(Stdout, WithTail(lines))); got != expected {
+			t.Fatalf("expected the last %d lines %q, got %q", lines, expected, got)
+		}
+	}
+
+	// Reading from the offset following a chunk resumes after the chunk.
+	// The order of the streams depends on the order the helper
+	// captured them in, so each stream is checked on its own.
+	chunks := read(Combined)
+	streams := map[Stream]string{}
+	for _, chunk := range chunks {
+		streams[chunk.Stream] += string(chunk.Data)
+	}
+
+	if streams[Stdout] != "one\ntwo\nthree\nfour\nfive" || streams[Stderr] != "err\n" || len(chunks) < 3 {
+		t.Fatalf("unexpected output %q", streams)
+	}
+
+	resumed := read(Combined, WithOffset(chunks[1].NextOffset))
+	if join(resumed) != join(chunks[2:]) || resumed[0].Offset != chunks[2].Offset {
+		t.Fatalf("expected the output after the second chunk, got %q", join(resumed))
+	}
+
+	for _, opts := range [][]OutputOption{
+		{WithOffset(-1)},
+		{WithOffset(1), WithTail(1)},
+	} {
+		_, err = box.Output(id, Combined, opts...)
+		if !errors.Is(err, ErrInvalidOffset) {
+			t.Fatalf("expected ErrInvalidOffset, got %v", err)
+		}
+	}
+
+	_, err = box.Output(id, Combined, WithTail(-1))
+	if !errors.Is(err, ErrInvalidTail) {
+		t.Fatalf("expected ErrInvalidTail, got %v", err)
+	}
+
+	// Without following, the output of a running process ends at the
+	// output captured so far.
+	id, err = box.Start("sh", "-c", "echo ready; sleep 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	for {
+		chunk, err := output.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(chunk.Data) > 0 {
+			break
+		}
+	}
+
+	if got := join(read(Stdout, WithFollow(false))); got != "ready\n" {
+		t.Fatalf("expected the output captured so far, got %q", got)
+	}
+
+	err = box.Stop(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Box_Output_JSONLines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	started := time.Now()
+	id, err := box.Start("sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := box.Output(id, Combined, WithJSONLines())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	expected := []struct {
+		stream string
+		data   string
+	}{
+		{"stdout", "out\n"},
+		{"stderr", "err\n"},
+	}
+
+	d := json.NewDecoder(output)
+	offset := int64(0)
+	last := started
+	for _, e := range expected {
+		var record struct {
+			Time       time.Time `json:"time"`
+			Stream     string    `json:"stream"`
+			Offset     int64     `json:"offset"`
+			NextOffset int64     `json:"next_offset"`
+			Data       string    `json:"data"`
+		}
+
+		err = d.Decode(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if record.Stream != e.stream || record.Data != e.data || record.Offset != offset {
+			t.Fatalf("expected %s record %q at offset %d, got %+v", e.stream, e.data, offset, record)
+		}
+
+		// The records are captured in order after the process started.
+		if record.Time.Before(last) || record.Time.After(time.Now()) {
+			t.Fatalf("unexpected capture time %s", record.Time)
+		}
+
+		offset = record.NextOffset
+		last = record.Time
+	}
+
+	var extra json.RawMessage
+	if err = d.Decode(&extra); err != io.EOF {
+		t.Fatalf("expected EOF, got %s, %v", extra, err)
+	}
+}
+
+// cpuTime returns the CPU time consumed by the test process.
+func cpuTime(b *testing.B) time.Duration {
+	b.Helper()
+
+	var usage syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// Benchmark_Box_Output_followers measures the CPU time consumed by the
+// readers following the output of one process, both while they wait for
+// output and for every line of output they read.
+func Benchmark_Box_Output_followers(b *testing.B) {
+	const followers = 200
+	const idle = time.Millisecond * 500
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("cat")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	input, err := box.Input(id)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer input.Close()
+
+	lines := make(chan struct{}, followers)
+	for i := 0; i < followers; i++ {
+		output, err := box.Output(id, Stdout)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer output.Close()
+
+		go func() {
+			r := bufio.NewReader(output)
+			for {
+				_, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				lines <- struct{}{}
+			}
+		}()
+	}
+
+	// The followers wait for output instead of polling it.
+	start := cpuTime(b)
+	time.Sleep(idle)
+	idleCPU := cpuTime(b) - start
+
+	b.ResetTimer()
+	start = cpuTime(b)
+
+	for i := 0; i < b.N; i++ {
+		_, err = input.Write([]byte("line\n"))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for j := 0; j < followers; j++ {
+			<-lines
+		}
+	}
+
+	b.StopTimer()
+	b.ReportMetric(float64(cpuTime(b)-start)/float64(b.N), "cpu-ns/op")
+	b.ReportMetric(float64(idleCPU)/float64(idle)*100, "idle-cpu-%")
+}
+
+func Test_Box_Archived(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	box, err := New(ctx, time.Millisecond*200, WithArchive(dir, Retention{Age: time.Hour}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "echo archived; echo oops >&2; exit 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The output is only archived once the process is released.
+	for event := range events {
+		if event.Type == EventExited {
+			_, _, err = box.Archived(id, Combined)
+			if !errors.Is(err, ErrProcessNotFound) {
+				t.Fatalf("expected ErrProcessNotFound before the release, got %v", err)
+			}
+		}
+	}
+
+	_, err = box.Output(id, Combined)
+	if !errors.Is(err, ErrProcessNotFound) {
+		t.Fatalf("expected the process to be released, got %v", err)
+	}
+
+	status, output, err := box.Archived(id, Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer output.Close()
+
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "archived\n" {
+		t.Fatalf("expected the archived output, got %q", data)
+	}
+
+	if !status.Exited || status.Code != 3 || status.Command != "sh" {
+		t.Fatalf("unexpected archived status %+v", status)
+	}
+
+	archived, err := box.ArchivedStatus(id)
+	if err != nil || archived.Code != status.Code || archived.Command != status.Command {
+		t.Fatalf("unexpected archived status %+v, %v", archived, err)
+	}
+
+	for _, id := range []string{"missing", "../" + filepath.Base(dir)} {
+		_, _, err = box.Archived(id, Stdout)
+		if !errors.Is(err, ErrProcessNotFound) {
+			t.Fatalf("expected ErrProcessNotFound for %q, got %v", id, err)
+		}
+	}
+
+	// The bundles which are no longer retained are removed.
+	pruned, err := New(ctx, time.Minute, WithArchive(dir, Retention{Bytes: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pruned.Cleanup()
+
+	_, _, err = box.Archived(id, Stdout)
+	if !errors.Is(err, ErrProcessNotFound) {
+		t.Fatalf("expected the bundle to be removed, got %v", err)
+	}
+
+	_, err = New(ctx, time.Minute, WithArchive(dir, Retention{Age: -1}))
+	if !errors.Is(err, ErrInvalidRetention) {
+		t.Fatalf("expected ErrInvalidRetention, got %v", err)
+	}
+}
+
+func Test_Box_Archived_prune(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Millisecond*50, WithArchive(t.TempDir(), Retention{Age: time.Millisecond * 500}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range events {
+	}
+
+	_, err = box.ArchivedStatus(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The expired bundle is removed without archiving another process.
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		_, err = box.ArchivedStatus(id)
+		if errors.Is(err, ErrProcessNotFound) {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the bundle to expire, got %v", err)
+		}
+
+		time.Sleep(time.Millisecond * 50)
+	}
+}
+
+func Test_Box_Stat_metadata(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	before := time.Now()
+
+	id, err := box.StartWith("sleep", []string{"10"}, WithIdentity("it/user/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The PID inside the namespace is recorded once the process
+	// is observed running.
+	var status Status
+	for i := 0; i < 50 && status.JobPID == 0; i++ {
+		status, err = box.Stat(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	// The isolated helper is PID 1 of the namespace.
+	if status.JobPID <= 1 || status.PID <= 0 {
+		t.Fatalf("expected the PIDs to be recorded, got %+v", status)
+	}
+
+	if status.Identity != "it/user/1" || strings.Join(status.Args, " ") != "10" {
+		t.Fatalf("expected the identity and the args to be recorded, got %+v", status)
+	}
+
+	if status.Started.Before(before) || !status.Finished.IsZero() || status.Duration <= 0 {
+		t.Fatalf("unexpected times of a running process %+v", status)
+	}
+
+	err = box.Stop(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for !status.Exited {
+		status, err = box.Stat(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if status.Finished.Before(status.Started) || status.Duration != status.Finished.Sub(status.Started) {
+		t.Fatalf("unexpected times of a finished process %+v", status)
+	}
+}
+
+func Test_Box_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Millisecond*500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("sh", "-c", "sleep 0.2; exit 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []EventType{EventStarted, EventExited, EventReleased}
+	for _, e := range expected {
+		event, ok := <-events
+		if !ok {
+			t.Fatalf("expected %s event, got a closed channel", e)
+		}
+
+		if event.Type != e {
+			t.Fatalf("expected %s event, got %s", e, event.Type)
+		}
+
+		if e != EventStarted && (!event.Status.Exited || event.Status.Code != 4) {
+			t.Fatalf("unexpected status %+v of %s event", event.Status, e)
+		}
+	}
+
+	if _, ok := <-events; ok {
+		t.Fatal("expected the channel to be closed after the release")
+	}
+
+	_, err = box.Watch(id)
+	if !errors.Is(err, ErrProcessNotFound) {
+		t.Fatalf("expected ErrProcessNotFound, got %v", err)
+	}
+}
+
+func Test_Box_Watch_replay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	id, err := box.Start("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		status, err := box.Stat(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Exited {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// Watchers of a finished process receive the transitions
+	// which already happened.
+	events, err := box.Watch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []EventType{EventStarted, EventExited} {
+		if event := <-events; event.Type != e {
+			t.Fatalf("expected %s event, got %s", e, event.Type)
+		}
+	}
+}
+
+func Test_Box_List(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	box, err := New(ctx, time.Minute*5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Cleanup()
+
+	running, err := box.StartWith("sleep", []string{"10"}, WithIdentity("alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = box.Stop(running) }()
+
+	exited, err := box.StartWith("true", nil, WithIdentity("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		status, err := box.Stat(exited)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Exited {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	tests := map[string]struct {
+		filter   Filter
+		expected []string
+	}{
+		"all":     {Filter{}, []string{running, exited}},
+		"running": {Filter{State: StateRunning}, []string{running}},
+		"exited":  {Filter{State: StateExited}, []string{exited}},
+		"command": {Filter{Command: "true"}, []string{exited}},
+		"owner":   {Filter{Owner: "alice"}, []string{running}},
+		"none":    {Filter{State: StateExited, Owner: "alice"}, nil},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			summaries, err := box.List(test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, s := range summaries {
+				ids = append(ids, s.ID)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(test.expected, ",") {
+				t.Fatalf("expected %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
+
+func tarDir(dir, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		hdr.Name, err = filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		err = tw.WriteHeader(hdr)
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
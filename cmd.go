@@ -1,38 +1,81 @@
 package sandbox
 
 import (
-	"crypto/rand"
 	"fmt"
 	"io"
-	"math/big"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
+	"go.benjiv.com/sandbox/internal/capture"
+	"go.benjiv.com/sandbox/internal/cgroups"
 	"go.benjiv.com/sandbox/internal/sig"
+	"go.benjiv.com/sandbox/internal/spec"
 )
 
 // NOTE: The naming of outPrefix here is to keep it from being
 // exported to users of the library since it is not part of the
 // public API.
-const outPrefix = "stdout-"
+const outPrefix = "output-"
+
+// linkPrefix is the prefix of the host end of the veth pair of a
+// bridged process, followed by the first linkIDLen characters of
+// the hex digits of the id of the process.
+const (
+	linkPrefix = "sbx"
+	linkIDLen  = 12
+)
+
+// pollInterval is the interval at which the helpers of adopted
+// processes are checked for exit.
+const pollInterval = time.Millisecond * 100
 
 // cmdTracker is a wrapper for the exec.Cmd instance
 // which handles the creation and execution of the
 // subprocess using the helper process.
 type cmdTracker struct {
-	id             int
-	command        string
-	args           []string
-	cmd            *exec.Cmd
+	rec            record
+	journal        journal
+	cgroup         string
+	proc           *os.Process
 	stdout         string
+	notifier       *capture.Notifier
 	releaseTimeout time.Duration
 	status         chan Status
 	output         chan io.ReadCloser
-	stop           chan struct{}
-	finished       chan int
+	input          chan io.WriteCloser
+	stdin          io.WriteCloser
+	control        chan io.Writer
+	resize         io.WriteCloser
+	stop           chan time.Duration
+	pause          chan pauseRequest
+	signal         chan signalRequest
+	grace          time.Duration
+	finished       chan spec.Termination
 	release        <-chan time.Time
+	exited         func(record)
+	released       func(record)
+	watch          chan (<-chan Event)
+	watchers       []chan Event
+	done           chan struct{}
+}
+
+// pauseRequest asks the cmdTracker to pause or resume the
+// process. The result is sent to the err channel.
+type pauseRequest struct {
+	paused bool
+	err    chan<- error
+}
+
+// signalRequest asks the cmdTracker to send the signal to the process,
+// or to its process group. The result is sent to the err channel.
+type signalRequest struct {
+	sig   syscall.Signal
+	group bool
+	err   chan<- error
 }
 
 // cmdInfo is a type enforced wrapper for the
@@ -40,93 +83,207 @@ type cmdTracker struct {
 // consumers are unable to modify the channels
 // in inappropriate ways.
 type cmdInfo struct {
-	id       int
-	stop     chan<- struct{}
+	id       string
+	alias    int64
+	tty      bool
+	stop     chan<- time.Duration
+	pause    chan<- pauseRequest
+	signal   chan<- signalRequest
 	status   <-chan Status
 	output   <-chan io.ReadCloser
-	finished <-chan int
+	input    <-chan io.WriteCloser
+	control  <-chan io.Writer
+	finished <-chan spec.Termination
+	watch    <-chan (<-chan Event)
+
+	// done is closed once the tracker of the process stops
+	// serving requests, so requests sent to it do not block.
+	done <-chan struct{}
 }
 
 // Create a new command instance using the helper binary
-// and the provided arguments.
+// and the command and arguments of the journal record.
 //
 // This function creates the underlying mapping for the stdout
-// and stderr of the
+// and stderr of the command and records the process in the
+// journal of the sandbox.
 func createCmd(
 	tempdir string,
+	cgroup string, // parent cgroup of the process
 	helper string, // path to helper process
 	releaseTimeout time.Duration,
-	command string,
-	args ...string,
+	grace time.Duration,
+	opts options,
+	rec record,
+	exited func(record),
+	released func(record),
 ) (cmdInfo, error) {
-	// TODO: This is not a valid solution for a production
-	// system due to the potential for collisions. This should
-	// be changed to a UUID instead to ensure the risk of a
-	// collision is mitigated.
-	id, err := rand.Int(rand.Reader, big.NewInt(10000))
+	rec.Output = filepath.Join(tempdir, outPrefix+rec.ID)
+	rec.Result = filepath.Join(tempdir, resultPrefix+rec.ID)
+	rec.TTY = opts.tty
+	rec.Address = opts.net.Address
+	rec.Identity = opts.identity
+	rec.OutputLimit = opts.output.Bytes
+	rec.OutputRing = opts.output.Mode == OutputRing
+
+	// The host end of the veth pair of a bridged process is
+	// named after the process, within the limit of the kernel
+	// for interface names.
+	if opts.net.Mode == NetworkBridged {
+		opts.net.Link = linkPrefix + strings.ReplaceAll(rec.ID, "-", "")[:linkIDLen]
+	}
+
+	s := spec.Spec{
+		CGroup:  cgroup,
+		Name:    rec.ID,
+		Limits:  opts.limits,
+		TTY:     opts.tty,
+		Result:  rec.Result,
+		RootFS:  opts.rootfs,
+		UIDMap:  opts.uidMap,
+		GIDMap:  opts.gidMap,
+		Network: opts.net,
+
+		OutputLimit: rec.OutputLimit,
+		OutputRing:  rec.OutputRing,
+	}
+
+	// Tarballs are extracted by the helper into a directory
+	// of the process.
+	if info, err := os.Stat(opts.rootfs); err == nil && !info.IsDir() {
+		rec.RootFS = filepath.Join(tempdir, rootfsPrefix+rec.ID)
+		s.Image = opts.rootfs
+		s.RootFS = rec.RootFS
+	}
+
+	// The job specification is handed to the helper which
+	// applies it before executing the command.
+	env, err := s.Environ()
 	if err != nil {
 		return cmdInfo{}, err
 	}
 
-	outputFile := filepath.Join(
-		tempdir,
-		fmt.Sprintf("%s%d", outPrefix, id),
-	)
-
 	// Initialize the helper command with
 	// the proper arguments.
 	cmd, err := createHelperCmd(
 		helper,
-		outputFile,
-		command,
-		args...,
+		rec.Output,
+		env,
+		rec.Command,
+		rec.Args...,
 	)
 	if err != nil {
 		return cmdInfo{}, err
 	}
 
+	// Create a pipe for the stdin of the subprocess. The
+	// read end is inherited by the helper which passes it
+	// on to the isolated command.
+	stdin, input, err := os.Pipe()
+	if err != nil {
+		return cmdInfo{}, err
+	}
+	cmd.Stdin = stdin
+
+	// Processes with a terminal get a control pipe which
+	// carries the terminal resize events to the helper.
+	var control, resize *os.File
+	if opts.tty {
+		control, resize, err = os.Pipe()
+		if err != nil {
+			_ = stdin.Close()
+			_ = input.Close()
+			return cmdInfo{}, err
+		}
+
+		// The control pipe becomes file descriptor
+		// spec.ControlFD in the helper.
+		cmd.ExtraFiles = []*os.File{control}
+	}
+
+	rec.Start = time.Now()
+	if opts.deadline > 0 {
+		rec.Deadline = rec.Start.Add(opts.deadline)
+	}
+
 	err = cmd.Start()
+
+	// The helper holds its own copies of the stdin, control
+	// and output files so the copies of this process are closed.
+	_ = stdin.Close()
+	if control != nil {
+		_ = control.Close()
+	}
+
+	if out, ok := cmd.Stdout.(*os.File); ok {
+		_ = out.Close()
+	}
+
 	if err != nil {
+		_ = input.Close()
+		if resize != nil {
+			_ = resize.Close()
+		}
+
+		return cmdInfo{}, err
+	}
+
+	// Record the helper in the journal so the process can be
+	// re-adopted if this process exits before the command.
+	rec.PID = cmd.Process.Pid
+	_, rec.Started, _ = procStat(rec.PID)
+
+	j := journal{dir: tempdir}
+	err = j.write(rec)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		_ = input.Close()
+		if resize != nil {
+			_ = resize.Close()
+		}
+
 		return cmdInfo{}, err
 	}
 
 	c := &cmdTracker{
-		id:             int(id.Int64()),
-		command:        command,
-		args:           args,
-		cmd:            cmd,
-		stdout:         outputFile,
+		rec:            rec,
+		journal:        j,
+		cgroup:         cgroup,
+		proc:           cmd.Process,
+		stdout:         rec.Output,
 		status:         make(chan Status),
 		output:         make(chan io.ReadCloser),
-		stop:           make(chan struct{}),
-		finished:       make(chan int),
+		input:          make(chan io.WriteCloser),
+		stdin:          input,
+		control:        make(chan io.Writer),
+		stop:           make(chan time.Duration),
+		pause:          make(chan pauseRequest),
+		signal:         make(chan signalRequest),
+		grace:          grace,
+		finished:       make(chan spec.Termination),
+		watch:          make(chan (<-chan Event)),
+		done:           make(chan struct{}),
 		releaseTimeout: releaseTimeout,
+		exited:         exited,
+		released:       released,
 	}
 
-	go func() {
-		defer close(c.finished)
+	// Only assign the resize pipe when it exists to avoid
+	// storing a typed nil in the interface.
+	if resize != nil {
+		c.resize = resize
+	}
 
+	go c.monitor(func() spec.Termination {
 		// NOTE: I am purposely ignoring this
 		// error since stderr and stdout are
-		// already being merged and the exit
-		// status is being checked.
-		err = cmd.Wait()
-		exitcode := 0
+		// already being captured and the
+		// termination is recorded by the helper.
+		_ = cmd.Wait()
 
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitcode = exitError.ExitCode()
-		}
-
-		for {
-			select {
-			// Adhere to release timer when finished.
-			case <-c.release:
-				return
-			// Push the exit code to the finished channel.
-			case c.finished <- exitcode:
-			}
-		}
-	}()
+		return result(rec.Result, cmd.ProcessState)
+	})
 
 	// Create the command tracker instance
 	// and start the internal goroutine for
@@ -134,48 +291,275 @@ func createCmd(
 	return c.init()
 }
 
+// adoptCmd creates a command tracker for a process recorded in the
+// journal by a previous instance of the sandbox. The helper of a process
+// which is still running is monitored until it exits. The stdin and the
+// terminal of an adopted process are not available since their pipes
+// were held by the previous instance.
+//
+// Processes which finished longer than the release timeout ago are
+// released instead and false is returned.
+func adoptCmd(
+	tempdir string,
+	cgroup string,
+	releaseTimeout time.Duration,
+	grace time.Duration,
+	rec record,
+	exited func(record),
+	released func(record),
+) (cmdInfo, bool, error) {
+	j := journal{dir: tempdir}
+
+	// The helper exited while no instance of the sandbox
+	// was monitoring it so the termination written by the
+	// helper is used.
+	if !rec.Exited && !running(rec) {
+		finished := time.Now()
+		if info, err := os.Stat(rec.Result); err == nil {
+			finished = info.ModTime()
+		}
+
+		rec.terminate(result(rec.Result, nil), finished)
+
+		err := j.write(rec)
+		if err != nil {
+			return cmdInfo{}, false, err
+		}
+
+		if exited != nil {
+			exited(rec)
+		}
+	}
+
+	if rec.Exited {
+		releaseTimeout -= time.Since(rec.Finished)
+		if releaseTimeout <= 0 {
+			if released != nil {
+				released(rec)
+			}
+
+			removeFiles(j, rec)
+			return cmdInfo{}, false, nil
+		}
+	}
+
+	// NOTE: FindProcess always succeeds on unix systems.
+	proc, err := os.FindProcess(rec.PID)
+	if err != nil {
+		return cmdInfo{}, false, err
+	}
+
+	c := &cmdTracker{
+		rec:            rec,
+		journal:        j,
+		cgroup:         cgroup,
+		proc:           proc,
+		stdout:         rec.Output,
+		status:         make(chan Status),
+		output:         make(chan io.ReadCloser),
+		input:          make(chan io.WriteCloser),
+		control:        make(chan io.Writer),
+		stop:           make(chan time.Duration),
+		pause:          make(chan pauseRequest),
+		signal:         make(chan signalRequest),
+		grace:          grace,
+		finished:       make(chan spec.Termination),
+		watch:          make(chan (<-chan Event)),
+		done:           make(chan struct{}),
+		releaseTimeout: releaseTimeout,
+		exited:         exited,
+		released:       released,
+	}
+
+	go c.monitor(func() spec.Termination {
+		// The helper is not a child of this process
+		// so it is polled until it exits.
+		for !rec.Exited && running(rec) {
+			time.Sleep(pollInterval)
+		}
+
+		if rec.Exited {
+			return spec.Termination{Code: rec.Code, Signal: rec.Signal, Error: rec.Error}
+		}
+
+		return result(rec.Result, nil)
+	})
+
+	info, err := c.init()
+	if err != nil {
+		return cmdInfo{}, false, err
+	}
+
+	return info, true, nil
+}
+
+// monitor waits for the process to exit using `wait` and pushes
+// the termination to the finished channel until it is released.
+func (c *cmdTracker) monitor(wait func() spec.Termination) {
+	defer close(c.finished)
+
+	term := wait()
+
+	for {
+		select {
+		// Adhere to release timer when finished.
+		case <-c.release:
+			return
+		// Push the termination to the finished channel.
+		case c.finished <- term:
+		}
+	}
+}
+
+// removeFiles removes the output, the exit code, the extracted
+// image and the journal entry of the process.
+//
+// Errors can be safely ignored since the sandbox removes the
+// complete temp directory.
+// TODO: use `go.devnw.com/event` library instead
+// to capture errors from routines in
+// the future.
+func removeFiles(j journal, rec record) {
+	_ = os.Remove(rec.Output)
+	_ = os.Remove(rec.Result)
+	_ = j.remove(rec.ID)
+
+	if rec.RootFS != "" {
+		_ = os.RemoveAll(rec.RootFS)
+	}
+}
+
 // init starts an routine for managing and acessing the
 // command instance
 func (c *cmdTracker) init() (cmdInfo, error) {
+	// The readers of the output wait for the helper to write
+	// to the output file instead of polling it.
+	c.notifier = capture.NewNotifier(c.stdout)
+
 	go func() {
 		var timeout <-chan time.Time
 		exited := false
-		exitcode := 0
 		finished := c.finished
 
+		// The next watcher is created ahead of time so
+		// it can be offered to the Box.
+		next := c.watcher(exited)
+
+		// The process is stopped once its deadline passes,
+		// including the deadline of an adopted process which
+		// passed while no instance of the sandbox was running.
+		// The processes of a stopped process are killed
+		// once the grace period of the stop passes.
+		var killAt time.Time
+		var kill <-chan time.Time
+
+		var deadline <-chan time.Time
+		if !c.rec.Deadline.IsZero() && !c.rec.Exited {
+			timer := time.NewTimer(time.Until(c.rec.Deadline))
+			defer timer.Stop()
+
+			deadline = timer.C
+		}
+
 		defer func() {
+			// No further requests are served.
+			close(c.done)
+
+			// The Box is told the process is released while
+			// its output still exists so it can be archived
+			// before the watchers are notified.
+			if c.released != nil {
+				c.released(c.rec)
+			}
+
+			// Notify the watchers the process is released
+			// and end their channels.
+			c.notify(EventReleased)
+			for _, w := range c.watchers {
+				close(w)
+			}
+
+			close(c.watch)
 			close(c.status)
 			close(c.output)
+			close(c.input)
+			close(c.control)
+
+			// Close the stdin of the subprocess so no
+			// further input can be written.
+			if c.stdin != nil {
+				_ = c.stdin.Close()
+			}
 
-			// Cleanup the output file
-			// Error can be safely ignored since
-			// the sandbox removes the complete temp
-			// directory.
-			// TODO: use `go.devnw.com/event` library instead
-			// to capture errors from routines in
-			// the future.
-			_ = os.Remove(c.stdout)
+			if c.resize != nil {
+				_ = c.resize.Close()
+			}
+
+			// Wake the readers of the output and cleanup
+			// the output file and the journal entry of the
+			// process.
+			_ = c.notifier.Close()
+			removeFiles(c.journal, c.rec)
 		}()
 
 		for {
+			// The PID of the process inside its namespace
+			// is recorded once the process is observed.
+			if c.rec.JobPID == 0 && !exited {
+				if pid := jobPID(c.rec.PID); pid != 0 {
+					c.rec.JobPID = pid
+					_ = c.journal.write(c.rec)
+				}
+			}
+
 			select {
 			case <-timeout:
 				return
-			case <-c.stop:
+			case grace := <-c.stop:
+				if exited {
+					continue
+				}
+
+				c.terminate(&c.rec.Stopped)
+				killAt, kill = escalate(killAt, kill, grace)
+			case <-deadline:
+				if exited {
+					continue
+				}
+
+				c.terminate(&c.rec.TimedOut)
+				killAt, kill = escalate(killAt, kill, c.grace)
+			case <-kill:
+				kill = nil
 				if exited {
 					continue
 				}
 
-				// NOTE: I am purposely ignoring this
-				// error as it is not critical to the
-				// operation of the command.
-				// TODO: use `go.devnw.com/event` library instead
-				// to capture errors from routines in
-				// the future.
-				_ = sig.Term(c.cmd)
-			case exitCode := <-finished:
+				c.kill()
+			case req := <-c.pause:
+				req.err <- c.setPaused(req.paused, exited)
+			case req := <-c.signal:
+				req.err <- c.sendSignal(req.sig, req.group, exited)
+			case term := <-finished:
 				exited = true
-				exitcode = exitCode
+
+				// Record the exit in the journal unless
+				// it was already recorded.
+				if !c.rec.Exited {
+					c.rec.terminate(term, time.Now())
+
+					// NOTE: I am purposely ignoring this
+					// error, a missing exit in the journal
+					// is recovered from the termination the
+					// helper writes.
+					_ = c.journal.write(c.rec)
+
+					if c.exited != nil {
+						c.exited(c.rec)
+					}
+				}
+
+				c.notify(EventExited)
 
 				// Setup timer to release resources
 				timer := time.NewTimer(c.releaseTimeout)
@@ -188,12 +572,12 @@ func (c *cmdTracker) init() (cmdInfo, error) {
 				// statement will continue to read
 				// from the finished channel.
 				finished = nil
-			case c.status <- Status{
-				Command: c.command,
-				Exited:  exited,
-				Code:    exitcode,
-			}:
+			case c.status <- c.rec.status():
 			case c.output <- c.reader(c.stdout):
+			case c.input <- c.stdin:
+			case c.control <- c.resize:
+			case c.watch <- next:
+				next = c.watcher(exited)
 			}
 		}
 	}()
@@ -202,14 +586,144 @@ func (c *cmdTracker) init() (cmdInfo, error) {
 	// channels to ensure that consumers cannot modify
 	// the cmdTracker in an improper manner.
 	return cmdInfo{
-		id:       c.id,
+		id:       c.rec.ID,
+		alias:    c.rec.Alias,
 		status:   c.status,
 		output:   c.output,
+		input:    c.input,
+		control:  c.control,
+		tty:      c.resize != nil,
 		stop:     c.stop,
+		pause:    c.pause,
+		signal:   c.signal,
 		finished: c.finished,
+		watch:    c.watch,
+		done:     c.done,
 	}, nil
 }
 
+// escalate returns the time the processes of a stopped process are killed
+// at and the channel which fires at that time, for a stop with the grace
+// period `grace`. A kill which was already scheduled by an earlier stop is
+// only moved forward.
+func escalate(killAt time.Time, kill <-chan time.Time, grace time.Duration) (time.Time, <-chan time.Time) {
+	at := time.Now().Add(grace)
+	if kill != nil && !at.Before(killAt) {
+		return killAt, kill
+	}
+
+	return at, time.After(grace)
+}
+
+// kill kills every process of the process except the helper, which records
+// the termination. Processes without a cgroup, e.g. of a rootless sandbox,
+// are killed along with their PID namespace by killing the isolated helper,
+// which is PID 1 of the namespace.
+func (c *cmdTracker) kill() {
+	err := cgroups.Kill(c.cgroup, c.rec.ID, c.rec.PID)
+	if err == nil {
+		return
+	}
+
+	if pid := firstChild(c.rec.PID); pid != 0 {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// terminate stops the process after journaling the reason it is stopped
+// for by setting `reason`, so the termination is reported for the reason
+// rather than the signal, even by a later instance.
+func (c *cmdTracker) terminate(reason *bool) {
+	if !*reason {
+		*reason = true
+		_ = c.journal.write(c.rec)
+	}
+
+	// A paused process is resumed so it can handle the
+	// signal.
+	_ = c.setPaused(false, false)
+
+	// NOTE: I am purposely ignoring this
+	// error as it is not critical to the
+	// operation of the command.
+	// TODO: use `go.devnw.com/event` library instead
+	// to capture errors from routines in
+	// the future.
+	_ = sig.TermProcess(c.proc)
+}
+
+// setPaused freezes or thaws every process of the process, unless it
+// exited, and journals whether the process is paused.
+func (c *cmdTracker) setPaused(paused, exited bool) error {
+	if exited {
+		return ErrProcessExited
+	}
+
+	if paused == c.rec.Paused {
+		return nil
+	}
+
+	freeze := cgroups.Thaw
+	if paused {
+		freeze = cgroups.Freeze
+	}
+
+	err := freeze(c.cgroup, c.rec.ID)
+	if err != nil {
+		return err
+	}
+
+	c.rec.Paused = paused
+	_ = c.journal.write(c.rec)
+
+	return nil
+}
+
+// sendSignal sends the signal to the process, or to every process of its
+// process group, unless it exited.
+func (c *cmdTracker) sendSignal(sig syscall.Signal, group, exited bool) error {
+	if exited {
+		return ErrProcessExited
+	}
+
+	pid := hostJobPID(c.rec.PID)
+	if pid == 0 {
+		return ErrProcessNotStarted
+	}
+
+	// The process leads its process group, see the isolated
+	// helper.
+	if group {
+		pid = -pid
+	}
+
+	return syscall.Kill(pid, sig)
+}
+
+// watcher creates the channel of a new watcher of the process and
+// replays the transitions which already happened to it.
+func (c *cmdTracker) watcher(exited bool) chan Event {
+	w := make(chan Event, eventTypes)
+	w <- Event{Type: EventStarted, Status: c.rec.status()}
+
+	if exited {
+		w <- Event{Type: EventExited, Status: c.rec.status()}
+	}
+
+	c.watchers = append(c.watchers, w)
+	return w
+}
+
+// notify sends the transition `t` to the watchers of the process. The
+// channels of the watchers are buffered for every transition so this
+// never blocks.
+func (c *cmdTracker) notify(t EventType) {
+	e := Event{Type: t, Status: c.rec.status()}
+	for _, w := range c.watchers {
+		w <- e
+	}
+}
+
 // reader opens a read only file and returns a file wrapper
 // which handles the EOF condition.
 func (c *cmdTracker) reader(file string) io.ReadCloser {
@@ -219,15 +733,17 @@ func (c *cmdTracker) reader(file string) io.ReadCloser {
 		return nil
 	}
 
-	return &fileWrapper{r, c.finished}
+	return &fileWrapper{File: r, finished: c.finished, notifier: c.notifier}
 }
 
 // createHelperCmd creates a new command instance for the
-// helper process, merges the stdout and stderr of the
-// command, and returns the command instance.
+// helper process, directs the captured output of the
+// command to the output file, passes the job specification
+// through the environment, and returns the command instance.
 func createHelperCmd(
 	path string,
 	stdout string,
+	env string,
 	command string,
 	args ...string,
 ) (*exec.Cmd, error) {
@@ -247,8 +763,18 @@ func createHelperCmd(
 		)...,
 	)
 
-	// Create a stdout file for the output of the
-	// subprocess.
+	cmd.Env = append(os.Environ(), env)
+
+	// Place the helper in its own process group so signals sent
+	// to the process group of this process, such as an interrupt
+	// from the terminal, do not terminate the processes which are
+	// re-adopted after a restart.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	// Create an output file for the output of the
+	// subprocess. The helper frames the stdout and
+	// stderr of the subprocess into separate streams
+	// within this file.
 	writer, err := os.OpenFile(
 		stdout,
 		os.O_CREATE|os.O_WRONLY,
@@ -258,18 +784,22 @@ func createHelperCmd(
 		return nil, err
 	}
 
-	// merge the stdout and stderr
 	cmd.Stdout = writer
-	cmd.Stderr = cmd.Stdout
 
 	return cmd, nil
 }
 
 // fileWrapper wraps a *os.File and adds a channel which is
-// closed when the command has finished.
+// closed when the command has finished, and the notifier of
+// the writes to the file.
 type fileWrapper struct {
 	*os.File
-	finished <-chan int
+	finished <-chan spec.Termination
+	notifier *capture.Notifier
+
+	// changed is taken from the notifier before every read
+	// so a write after the read wakes the reader.
+	changed <-chan struct{}
 }
 
 // Read overrides the underlying Read method to check if the
@@ -277,25 +807,36 @@ type fileWrapper struct {
 // reached an EOF and the command is finished, it will return
 // ErrCommandFinished.
 func (f *fileWrapper) Read(p []byte) (n int, err error) {
+	// The command is checked before reading since all of the
+	// output is written before the command finishes. Checking
+	// after reading could miss output written between the read
+	// and the check.
+	finished := false
+	select {
+	case <-f.finished:
+		finished = true
+	default:
+	}
+
+	f.changed = f.notifier.Changed()
 	n, err = f.File.Read(p)
 
 	// If the command has finished and the reader returned
 	// end of file then return the ErrCommandFinished to tell
 	// the caller that the command has finished and the reader
 	// should be closed.
-	select {
-	case <-f.finished:
-		if err == io.EOF {
-			return n, err
-		}
-	default:
-	}
-
-	// Override the EOF error because the command
+	//
+	// Otherwise override the EOF error because the command
 	// is not complete.
-	if err == io.EOF {
+	if err == io.EOF && !finished {
 		err = nil
 	}
 
 	return n, err
 }
+
+// notified returns the channel which is closed once the file
+// is written to after the last read, and the finished channel.
+func (f *fileWrapper) notified() (<-chan struct{}, <-chan spec.Termination) {
+	return f.changed, f.finished
+}
@@ -1,19 +1,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
+	"go.benjiv.com/sandbox"
 	"go.benjiv.com/sandbox/cmd/internal"
+	"go.benjiv.com/sandbox/internal/pty"
 	pb "go.benjiv.com/sandbox/proto"
 )
 
@@ -37,7 +44,9 @@ func main() {
 			host string,
 			args []string,
 		) error {
-			if len(args) < 3 {
+			// The subcommands validate their own arguments
+			// since list does not require any.
+			if len(args) < 2 {
 				return fmt.Errorf("missing command")
 			}
 
@@ -61,8 +70,22 @@ func main() {
 				return c.stop(ctx, args[2:])
 			case "stat":
 				return c.stat(ctx, args[2:])
+			case "pause":
+				return c.pause(ctx, args[2:])
+			case "resume":
+				return c.resume(ctx, args[2:])
+			case "signal":
+				return c.signal(ctx, args[2:])
 			case "output":
 				return c.output(ctx, args[2:])
+			case "input":
+				return c.input(ctx, args[2:])
+			case "attach":
+				return c.attach(ctx, args[2:])
+			case "watch":
+				return c.watch(ctx, args[2:])
+			case "list":
+				return c.list(ctx, args[2:])
 			default:
 				return internal.ErrFlag
 			}
@@ -79,13 +102,68 @@ type svcClient struct {
 }
 
 func (c svcClient) start(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("start", flag.ContinueOnError)
+	memory := fs.Int64("memory", 0, "The maximum memory of the command in bytes")
+	cpuQuota := fs.Int64("cpu_quota", 0, "The CPU time in microseconds the command may use each period")
+	cpuPeriod := fs.Int64("cpu_period", 0, "The CPU accounting period in microseconds")
+	pids := fs.Int64("pids", 0, "The maximum number of processes of the command")
+	ioDevice := fs.String("io_device", "", "The block device (major:minor) the io limits apply to")
+	ioRead := fs.Int64("io_read_bps", 0, "The maximum bytes read per second from io_device")
+	ioWrite := fs.Int64("io_write_bps", 0, "The maximum bytes written per second to io_device")
+	tty := fs.Bool("tty", false, "Allocate a terminal for the command to use with attach")
+	rootfs := fs.String("rootfs", "", "The name of the root filesystem image on the server")
+	network := fs.String("network", "none", "The network mode of the command: none, loopback or bridged")
+	deadline := fs.Duration("deadline", 0, "The maximum runtime of the command after which it is stopped")
+	outputLimit := fs.Int64("output_limit", 0, "The number of bytes of output retained for the command")
+	outputMode := fs.String("output_mode", "truncate", "The output retained at the output limit: truncate or ring")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("start: %v", err)
+	}
+
+	args = fs.Args()
 	if len(args) < 1 {
 		return fmt.Errorf("start: missing command")
 	}
 
+	mode, ok := pb.Network_value[strings.ToUpper(*network)]
+	if !ok {
+		return fmt.Errorf("start: invalid network %s", *network)
+	}
+
+	outMode, ok := pb.OutputLimit_Mode_value[strings.ToUpper(*outputMode)]
+	if !ok {
+		return fmt.Errorf("start: invalid output mode %s", *outputMode)
+	}
+
+	limits := &pb.Limits{
+		Memory:    *memory,
+		CpuQuota:  *cpuQuota,
+		CpuPeriod: *cpuPeriod,
+		Pids:      *pids,
+	}
+
+	if *ioDevice != "" {
+		limits.Io = []*pb.IOLimit{{
+			Device:   *ioDevice,
+			ReadBps:  *ioRead,
+			WriteBps: *ioWrite,
+		}}
+	}
+
 	p, err := c.Start(ctx, &pb.Command{
-		Command: args[0],
-		Args:    args[1:],
+		Command:  args[0],
+		Args:     args[1:],
+		Limits:   limits,
+		Tty:      *tty,
+		Rootfs:   *rootfs,
+		Network:  pb.Network(mode),
+		Deadline: deadline.Microseconds(),
+		OutputLimit: &pb.OutputLimit{
+			Bytes: *outputLimit,
+			Mode:  pb.OutputLimit_Mode(outMode),
+		},
 	})
 
 	if err != nil {
@@ -98,9 +176,10 @@ func (c svcClient) start(ctx context.Context, args []string) error {
 	}
 
 	c.log.Printf(
-		"started command [%s]%s; id: %d",
+		"started command [%s]%s; id: %s (alias %d)",
 		args[0],
 		a,
+		p.Uuid,
 		p.Id,
 	)
 
@@ -108,66 +187,181 @@ func (c svcClient) start(ctx context.Context, args []string) error {
 }
 
 func (c svcClient) stop(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stop", flag.ContinueOnError)
+	grace := fs.Duration(
+		"grace_period",
+		0,
+		"The time the command is given to exit before it is killed, defaults to the grace period of the server",
+	)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("stop: %v", err)
+	}
+
+	args = fs.Args()
 	if len(args) < 1 {
 		return fmt.Errorf("stop: missing ID")
 	}
 
-	id, err := strconv.Atoi(args[0])
+	p := parseID(args[0])
+
+	s, err := c.Stop(ctx, &pb.StopRequest{
+		Id:          p.Id,
+		Uuid:        p.Uuid,
+		GracePeriod: grace.Microseconds(),
+	})
 	if err != nil {
 		return err
 	}
 
-	var s *pb.Status
-	s, err = c.Stop(ctx, &pb.Process{
-		Id: int64(id),
-	})
+	c.log.Print(statusString(args[0], s))
+	return nil
+}
+
+func (c svcClient) pause(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("pause: missing ID")
+	}
+
+	s, err := c.Pause(ctx, parseID(args[0]))
 	if err != nil {
-		return err
+		return fmt.Errorf("could not pause process: %v", err)
 	}
 
-	c.log.Print(statusString(int64(id), s))
+	c.log.Print(statusString(args[0], s))
 	return nil
 }
 
-func (c svcClient) stat(ctx context.Context, args []string) error {
+func (c svcClient) resume(ctx context.Context, args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("stop: missing ID")
+		return fmt.Errorf("resume: missing ID")
 	}
 
-	id, err := strconv.Atoi(args[0])
+	s, err := c.Resume(ctx, parseID(args[0]))
 	if err != nil {
-		return err
+		return fmt.Errorf("could not resume process: %v", err)
 	}
 
-	var s *pb.Status
-	s, err = c.Stat(ctx, &pb.Process{
-		Id: int64(id),
+	c.log.Print(statusString(args[0], s))
+	return nil
+}
+
+func (c svcClient) signal(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("signal", flag.ContinueOnError)
+	group := fs.Bool("group", false, "Send the signal to every process of the process group of the command")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("signal: %v", err)
+	}
+
+	args = fs.Args()
+	if len(args) < 2 { //nolint:gomnd // signal and ID
+		return fmt.Errorf("signal: missing signal or ID")
+	}
+
+	sig, err := sandbox.ParseSignal(args[0])
+	if err != nil {
+		return fmt.Errorf("signal: %v", err)
+	}
+
+	p := parseID(args[1])
+
+	s, err := c.Signal(ctx, &pb.SignalRequest{
+		Id:     p.Id,
+		Uuid:   p.Uuid,
+		Signal: int32(sig),
+		Group:  *group,
 	})
+	if err != nil {
+		return fmt.Errorf("could not signal process: %v", err)
+	}
+
+	c.log.Print(statusString(args[1], s))
+	return nil
+}
+
+func (c svcClient) stat(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("stop: missing ID")
+	}
+
+	p := parseID(args[0])
+
+	s, err := c.Stat(ctx, p)
 	if err != nil {
 		return fmt.Errorf("could not get status: %v", err)
 	}
 
-	c.log.Print(statusString(int64(id), s))
+	c.log.Print(statusString(args[0], s))
+	if s.StartTime != 0 {
+		c.log.Print(processString(s))
+	}
+
+	if s.Usage != nil {
+		c.log.Print(usageString(s.Usage))
+	}
+
 	return nil
 }
 
 func (c svcClient) output(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("output", flag.ContinueOnError)
+	stream := fs.String(
+		"stream",
+		"interleaved",
+		"The output stream to read: combined, stdout, stderr or interleaved",
+	)
+	offset := fs.Int64("offset", 0, "The offset to read the output from, e.g. to resume a dropped stream")
+	tail := fs.Int64("tail", 0, "Read the output from the start of the last lines")
+	follow := fs.Bool("follow", true, "Stream the output until the command exits")
+	timestamps := fs.Bool("timestamps", false, "Prefix every line of output with the time it was captured at")
+	jsonLines := fs.Bool("json", false, "Write every record of output as a line of JSON")
+	archived := fs.Bool("archived", false, "Read the output of a released command from the archive of the server")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("output: %v", err)
+	}
+
+	args = fs.Args()
 	if len(args) < 1 {
 		return fmt.Errorf("output: missing ID")
 	}
 
-	id, err := strconv.Atoi(args[0])
-	if err != nil {
-		return err
+	selected, ok := pb.Stream_value[strings.ToUpper(*stream)]
+	if !ok {
+		return fmt.Errorf("output: invalid stream %s", *stream)
 	}
 
-	output, err := c.Output(ctx, &pb.Process{
-		Id: int64(id),
+	p := parseID(args[0])
+
+	output, err := c.Output(ctx, &pb.OutputRequest{
+		Id:        p.Id,
+		Uuid:      p.Uuid,
+		Stream:    pb.Stream(selected),
+		Offset:    *offset,
+		TailLines: *tail,
+		NoFollow:  !*follow,
+		Records:   *timestamps || *jsonLines,
+		Archived:  *archived,
 	})
 	if err != nil {
 		return fmt.Errorf("could not get output stream: %v", err)
 	}
 
+	printer := &outputPrinter{
+		interleaved: pb.Stream(selected) == pb.Stream_INTERLEAVED,
+		timestamps:  *timestamps,
+		jsonLines:   *jsonLines,
+		midLine:     map[*os.File]bool{},
+	}
+
+	// The offset following the last chunk received resumes the
+	// output when the stream is dropped.
+	next := *offset
+
 	var msg *pb.CommandOutput
 stream:
 	for {
@@ -180,20 +374,353 @@ stream:
 				break stream
 			}
 
-			_, err = os.Stdout.Write(msg.Data)
+			err = printer.print(msg)
 			if err != nil {
-				c.log.Errorf("error while writing to stdout: %s", err)
+				c.log.Errorf("error while writing output: %s", err)
 			}
+
+			next = msg.NextOffset
 		}
 	}
 
 	if err != nil && err != io.EOF {
-		return fmt.Errorf("output stream error: %v", err)
+		return fmt.Errorf("output stream error (resume with -offset %d): %v", next, err)
+	}
+
+	return nil
+}
+
+// outputPrinter writes the output received from the server to the local
+// stdout, and the stderr for interleaved output, either as raw output,
+// with every line prefixed with its capture time, or as JSON Lines.
+type outputPrinter struct {
+	interleaved bool
+	timestamps  bool
+	jsonLines   bool
+
+	// midLine records the files whose last output did not end a line.
+	midLine map[*os.File]bool
+}
+
+// print writes a chunk of output.
+func (p *outputPrinter) print(msg *pb.CommandOutput) error {
+	chunk := sandbox.Chunk{
+		Stream:     sandbox.Stdout,
+		Data:       msg.Data,
+		Offset:     msg.Offset,
+		NextOffset: msg.NextOffset,
+	}
+
+	if msg.Stream == pb.Stream_STDERR {
+		chunk.Stream = sandbox.Stderr
+	}
+
+	if msg.Time != 0 {
+		chunk.Time = time.UnixMicro(msg.Time)
+	}
+
+	if p.jsonLines {
+		return json.NewEncoder(os.Stdout).Encode(chunk)
+	}
+
+	// Interleaved stderr output is written to the local stderr.
+	w := os.Stdout
+	if chunk.Stream == sandbox.Stderr && p.interleaved {
+		w = os.Stderr
+	}
+
+	if !p.timestamps {
+		_, err := w.Write(chunk.Data)
+		return err
+	}
+
+	// Output captured before output was timestamped has no time.
+	stamp := "-"
+	if !chunk.Time.IsZero() {
+		stamp = chunk.Time.Format(time.RFC3339Nano)
+	}
+
+	data := chunk.Data
+	for len(data) > 0 {
+		if !p.midLine[w] {
+			_, err := fmt.Fprintf(w, "%s ", stamp)
+			if err != nil {
+				return err
+			}
+		}
+
+		n := bytes.IndexByte(data, '\n') + 1
+		if n == 0 {
+			n = len(data)
+		}
+
+		_, err := w.Write(data[:n])
+		if err != nil {
+			return err
+		}
+
+		p.midLine[w] = data[n-1] != '\n'
+		data = data[n:]
+	}
+
+	return nil
+}
+
+// watch prints the status transitions of the process until it is released
+// by the server.
+func (c svcClient) watch(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("watch: missing ID")
+	}
+
+	events, err := c.Watch(ctx, parseID(args[0]))
+	if err != nil {
+		return fmt.Errorf("could not watch process: %v", err)
+	}
+
+	for {
+		e, err := events.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("watch stream error: %v", err)
+		}
+
+		c.log.Printf("%s: %s", e.Type, statusString(args[0], e.Status))
+	}
+}
+
+// list prints the status of the processes selected by the flags which the
+// client is allowed to see.
+func (c svcClient) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	state := fs.String("state", "any", "The state of the listed processes: any, running or exited")
+	command := fs.String("command", "", "Only list the processes of the command")
+	owner := fs.String("owner", "", "Only list the processes started by the identity")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("list: %v", err)
+	}
+
+	selected, ok := pb.ListRequest_State_value[strings.ToUpper(*state)]
+	if !ok {
+		return fmt.Errorf("list: invalid state %s", *state)
+	}
+
+	resp, err := c.List(ctx, &pb.ListRequest{
+		State:   pb.ListRequest_State(selected),
+		Command: *command,
+		Owner:   *owner,
+	})
+	if err != nil {
+		return fmt.Errorf("could not list processes: %v", err)
+	}
+
+	for _, job := range resp.Jobs {
+		c.log.Printf(
+			"%s (alias %d; owner %s)",
+			statusString(job.Process.Uuid, job.Status),
+			job.Process.Id,
+			job.Status.Identity,
+		)
 	}
 
 	return nil
 }
 
+// input streams a local file, or the stdin of the client when no file is
+// provided, to the stdin of the process.
+func (c svcClient) input(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("input: missing ID")
+	}
+
+	p := parseID(args[0])
+
+	var r io.Reader = os.Stdin
+	if len(args) > 1 {
+		f, err := os.Open(args[1])
+		if err != nil {
+			return fmt.Errorf("input: %v", err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	stream, err := c.Input(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get input stream: %v", err)
+	}
+
+	// The first message carries the process ID even when there is no
+	// input so the server is able to close the stdin of the process.
+	err = stream.Send(&pb.CommandInput{Id: p.Id, Uuid: p.Uuid})
+	if err != nil {
+		return fmt.Errorf("input stream error: %v", err)
+	}
+
+	buff := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buff)
+		if n > 0 {
+			sendErr := stream.Send(&pb.CommandInput{
+				Id:   p.Id,
+				Uuid: p.Uuid,
+				Data: buff[:n],
+			})
+			if sendErr != nil {
+				return fmt.Errorf("input stream error: %v", sendErr)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("error while reading input: %v", err)
+		}
+	}
+
+	s, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("input stream error: %v", err)
+	}
+
+	c.log.Print(statusString(args[0], s))
+	return nil
+}
+
+// detachKey is the keystroke (Ctrl-]) which detaches the client from the
+// terminal of the process without stopping the process.
+const detachKey = 0x1d
+
+// attach connects the local terminal to the terminal of the process. The
+// local terminal is put in raw mode so every keystroke is sent to the
+// process and the size of the local terminal is kept in sync with the
+// terminal of the process.
+func (c svcClient) attach(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("attach: missing ID")
+	}
+
+	p := parseID(args[0])
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.Attach(ctx)
+	if err != nil {
+		return fmt.Errorf("could not attach: %v", err)
+	}
+
+	// gRPC streams do not support concurrent sends so every
+	// message is sent from a single routine.
+	requests := make(chan *pb.AttachRequest)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case req := <-requests:
+				req.Id = p.Id
+				req.Uuid = p.Uuid
+				if stream.Send(req) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	send := func(req *pb.AttachRequest) {
+		select {
+		case <-ctx.Done():
+		case requests <- req:
+		}
+	}
+
+	size := func() *pb.TerminalSize {
+		s, err := pty.GetSize(os.Stdin)
+		if err != nil {
+			return nil
+		}
+
+		return &pb.TerminalSize{Rows: uint32(s.Rows), Cols: uint32(s.Cols)}
+	}
+
+	// The first message identifies the process and carries the
+	// initial size of the terminal.
+	send(&pb.AttachRequest{Size: size()})
+
+	restore, err := pty.MakeRaw(os.Stdin)
+	if err == nil {
+		defer func() { _ = restore() }()
+	}
+
+	resize := make(chan os.Signal, 1)
+	pty.NotifyResize(resize)
+	defer signal.Stop(resize)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-resize:
+				send(&pb.AttachRequest{Size: size()})
+			}
+		}
+	}()
+
+	go func() {
+		buff := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buff)
+			if n > 0 {
+				data := make([]byte, n)
+				copy(data, buff[:n])
+
+				if i := bytes.IndexByte(data, detachKey); i >= 0 {
+					send(&pb.AttachRequest{Data: data[:i]})
+					cancel()
+					return
+				}
+
+				send(&pb.AttachRequest{Data: data})
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("attach stream error: %v", err)
+		}
+
+		w := os.Stdout
+		if msg.Stream == pb.Stream_STDERR {
+			w = os.Stderr
+		}
+
+		_, err = w.Write(msg.Data)
+		if err != nil {
+			c.log.Errorf("error while writing output: %s", err)
+		}
+	}
+}
+
 func newgRPCClient(
 	ctx context.Context,
 	config *tls.Config,
@@ -213,13 +740,87 @@ func newgRPCClient(
 	return conn, pb.NewCommandServiceClient(conn), nil
 }
 
-func statusString(id int64, status *pb.Status) string {
+// parseID parses the process ID argument. Numeric IDs are sent as the
+// alias of the process for compatibility with servers and scripts which
+// predate string IDs.
+func parseID(arg string) *pb.Process {
+	alias, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return &pb.Process{Uuid: arg}
+	}
+
+	return &pb.Process{Id: alias}
+}
+
+func statusString(id string, status *pb.Status) string {
 	procStatus := "RUNNING"
+	if status.Paused {
+		procStatus = "PAUSED"
+	}
+
 	if status.Exited {
+		// Servers which predate the termination reason
+		// do not set it.
+		reason := status.Reason.String()
+		if status.Reason == pb.Reason_RUNNING {
+			reason = "EXITED"
+		}
+
 		procStatus = fmt.Sprintf(
-			"EXITED; exit code: %d",
+			"%s; exit code: %d",
+			reason,
 			int(status.Exitcode),
 		)
+
+		if status.Signal != 0 {
+			procStatus += fmt.Sprintf("; signal: %s", syscall.Signal(status.Signal))
+		}
+
+		if status.Error != "" {
+			procStatus += fmt.Sprintf("; error: %s", status.Error)
+		}
 	}
-	return fmt.Sprintf("process %d: %s", id, procStatus)
+
+	if status.Truncated {
+		procStatus += "; output truncated"
+	}
+	return fmt.Sprintf("process %s: %s", id, procStatus)
+}
+
+func processString(status *pb.Status) string {
+	out := fmt.Sprintf(
+		"command: %s; started: %s",
+		strings.Join(append([]string{status.Command}, status.Args...), " "),
+		time.UnixMicro(status.StartTime).Format(time.RFC3339),
+	)
+
+	if status.EndTime != 0 {
+		out += fmt.Sprintf("; finished: %s", time.UnixMicro(status.EndTime).Format(time.RFC3339))
+	}
+
+	if status.DeadlineTime != 0 {
+		out += fmt.Sprintf("; deadline: %s", time.UnixMicro(status.DeadlineTime).Format(time.RFC3339))
+	}
+
+	return out + fmt.Sprintf(
+		"; duration: %s; pid: %d; job pid: %d; identity: %s",
+		time.Duration(status.Duration)*time.Microsecond,
+		status.Pid,
+		status.JobPid,
+		status.Identity,
+	)
+}
+
+func usageString(usage *pb.Usage) string {
+	return fmt.Sprintf(
+		"memory: %d bytes (peak %d bytes); cpu: %s (throttled %d periods); "+
+			"io: %d bytes read, %d bytes written; pids: %d",
+		usage.Memory,
+		usage.MemoryPeak,
+		time.Duration(usage.CpuTime)*time.Microsecond,
+		usage.ThrottledPeriods,
+		usage.IoReadBytes,
+		usage.IoWriteBytes,
+		usage.Pids,
+	)
 }
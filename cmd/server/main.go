@@ -27,6 +27,43 @@ var config []byte
 var timeoutText = `The timeout for releasing a commands resources after the command exits. Valid time units are "ns", "us"
     (or "µs"), "ms", "s", "m", "h".`
 
+var stateDirText = `The directory used to persist the state of the sandbox. Running commands are left running on shutdown
+    and are re-adopted, along with the status and output of finished commands, when the server restarts.`
+
+var policyText = `The policy selecting the clients allowed to act on a command started by another client: "owner" only allows
+    clients with an "any_job" or "*" role, "command" allows every client allowed to run the command.`
+
+var graceText = `The time a stopped command is given to exit before every process of the command is killed. Valid time units
+    are "ns", "us" (or "µs"), "ms", "s", "m", "h".`
+
+var outputBudgetText = `The number of bytes of output retained for all commands. Every command reserves its output limit from
+    the budget until it is released, so running commands and finished commands which are not released yet share the
+    budget. Starting a command fails with "output budget exhausted" when its output limit exceeds what is left of the
+    budget. Zero disables the budget.`
+
+var outputLimitText = `The number of bytes of output retained for a command started without an output limit. Defaults to
+    1MiB, or the budget when it is smaller, when a budget is set, otherwise zero disables the limit. The budget holds
+    output_budget/output_limit of these commands at once.`
+
+var outputModeText = `The output retained once a command reaches its output limit: "truncate" retains the first output and
+    "ring" retains the most recent output.`
+
+var archiveDirText = `The directory the output and final status of finished commands are archived to when they
+    are released, so clients can read the output of released commands. Empty disables the archive.`
+
+var archiveAgeText = `The time the archive of a command is kept after the command is released. Zero keeps the
+    archives until the archive exceeds archive_bytes.`
+
+var archiveBytesText = `The number of bytes of archives kept, removing the oldest archives first. Zero does not
+    bound the size of the archive.`
+
+var imageDirText = `The directory holding the images commands may use as their root filesystem. The directory must be
+    owned by the user of the server and not writable by other users. Empty disables root filesystems.`
+
+// defaultOutputLimit is the output limit of a command started without an
+// output limit when the server has an output budget.
+const defaultOutputLimit = 1 << 20
+
 func main() {
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
 	caFile := fs.String("ca_file", "../../certs/ca.cert", "The file containing the CA root cert file")
@@ -34,6 +71,16 @@ func main() {
 	keyFile := fs.String("key_file", "../../certs/server.key", "The file containing the CA root cert file")
 	serverAddr := fs.String("addr", "127.0.0.1:50000", "The server address in the format of host:port")
 	releaseTimeout := fs.Duration("releaseTimeout", time.Minute*5, timeoutText)
+	stateDir := fs.String("state_dir", "", stateDirText)
+	policy := fs.String("job_policy", string(pb.PolicyOwner), policyText)
+	grace := fs.Duration("grace_period", time.Second*10, graceText)
+	outputBudget := fs.Int64("output_budget", 0, outputBudgetText)
+	outputLimit := fs.Int64("output_limit", 0, outputLimitText)
+	outputMode := fs.String("output_mode", string(sandbox.OutputTruncate), outputModeText)
+	archiveDir := fs.String("archive_dir", "", archiveDirText)
+	archiveAge := fs.Duration("archive_age", 0, archiveAgeText)
+	archiveBytes := fs.Int64("archive_bytes", 0, archiveBytesText)
+	imageDir := fs.String("image_dir", "", imageDirText)
 
 	err := internal.Cli(
 		fs,
@@ -64,21 +111,70 @@ func main() {
 				grpc.Creds(credentials.NewTLS(cfg)),
 			}
 
-			box, err := sandbox.New(ctx, *releaseTimeout)
+			boxOpts := []sandbox.BoxOption{sandbox.WithGracePeriod(*grace)}
+			if *stateDir != "" {
+				boxOpts = append(boxOpts, sandbox.WithStateDir(*stateDir))
+			}
+
+			// The output limit of a command does not depend on the
+			// size of the budget, only on what a command needs.
+			if *outputBudget > 0 && *outputLimit == 0 {
+				*outputLimit = defaultOutputLimit
+				if *outputBudget < *outputLimit {
+					*outputLimit = *outputBudget
+				}
+			}
+
+			if *outputLimit > 0 {
+				boxOpts = append(boxOpts, sandbox.WithOutputBudget(
+					*outputBudget,
+					sandbox.OutputLimit{Bytes: *outputLimit, Mode: sandbox.OutputMode(*outputMode)},
+				))
+			}
+
+			if *archiveDir != "" {
+				boxOpts = append(boxOpts, sandbox.WithArchive(
+					*archiveDir,
+					sandbox.Retention{Age: *archiveAge, Bytes: *archiveBytes},
+				))
+			}
+
+			if *imageDir != "" {
+				boxOpts = append(boxOpts, sandbox.WithImageDir(*imageDir))
+			}
+
+			box, err := sandbox.New(ctx, *releaseTimeout, boxOpts...)
 			if err != nil {
 				return err
 			}
 			defer func() {
+				// The processes are left running with a state
+				// directory so they are re-adopted on restart.
+				if *stateDir != "" {
+					lg.Printf("leaving sandbox state in %s", *stateDir)
+					return
+				}
+
 				box.Cleanup()
 				lg.Print("cleaned up sandbox")
 			}()
 
 			lg.Printf("sandbox created")
+			if *outputBudget > 0 {
+				lg.Printf(
+					"output budget of %d bytes holds %d commands without an output limit until they are released",
+					*outputBudget,
+					*outputBudget / *outputLimit,
+				)
+			}
+			if box.Rootless() {
+				lg.Print("running rootless, resource limits require a writable cgroup hierarchy")
+			}
 
 			grpcServer := grpc.NewServer(opts...)
 
 			// Initialize the server and register the services.
-			cmdSvr, err := pb.NewServer(lg, box, roles)
+			cmdSvr, err := pb.NewServer(lg, box, roles, pb.WithPolicy(pb.Policy(*policy)))
 			if err != nil {
 				return err
 			}
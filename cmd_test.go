@@ -40,7 +40,7 @@ func (i Info) String() string {
 }
 
 func Test_Reflect(t *testing.T) {
-	tempdir, helper, err := deployHelper()
+	tempdir, helper, err := deployHelper("")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -49,26 +49,38 @@ func Test_Reflect(t *testing.T) {
 		os.RemoveAll(tempdir)
 	})
 
+	id, err := newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	cmd, err := createCmd(
 		tempdir,
+		cgroupPrefix+id,
 		helper,
 		time.Minute*5,
-		"./test/bin/reflector",
+		time.Second,
+		options{},
+		record{ID: id, Command: "./test/bin/reflector"},
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	output, ok := <-cmd.output
+	rc, ok := <-cmd.output
 	if !ok {
 		t.Fatal("channel close prematurely")
 	}
+
+	output := newOutputReader(rc, Combined)
 	defer output.Close()
 
 	d := gob.NewDecoder(output)
 	for i := 0; i < 2; i++ {
 		if i > 0 {
-			cmd.stop <- struct{}{}
+			cmd.stop <- time.Second
 		}
 
 		info := Info{}
@@ -97,7 +109,7 @@ func Test_Reflect(t *testing.T) {
 }
 
 func Test_createCmd_parallel_read(t *testing.T) {
-	tempdir, helper, err := deployHelper()
+	tempdir, helper, err := deployHelper("")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -106,11 +118,21 @@ func Test_createCmd_parallel_read(t *testing.T) {
 		os.RemoveAll(tempdir)
 	})
 
+	id, err := newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	info, err := createCmd(
 		tempdir,
+		cgroupPrefix+id,
 		helper,
 		time.Minute*5,
-		"tree",
+		time.Second,
+		options{},
+		record{ID: id, Command: "tree"},
+		nil,
+		nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -124,10 +146,12 @@ func Test_createCmd_parallel_read(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		go func() {
 			defer wg.Done()
-			r, ok := <-info.output
+			rc, ok := <-info.output
 			if !ok {
 				t.Error("expected output")
 			}
+
+			r := newOutputReader(rc, Combined)
 			defer r.Close()
 
 			fullOutput := make([]byte, 0, 1024)
@@ -2,6 +2,7 @@ package sandbox
 
 import (
 	_ "embed"
+	"encoding/json"
 	"os"
 	"path/filepath"
 )
@@ -13,10 +14,6 @@ const (
 	// helperCmd is the name of the helper binary
 	helperCmd = "prochelper"
 
-	// constraintsConfig is the name of the resource limits file
-	// TODO: This should probably be a configurable option. Hard coded for now.
-	constraintsConfig = "constraints.json"
-
 	// sandboxPattern is the name of the sandbox directory
 	sandboxPattern = "sandbox"
 )
@@ -26,31 +23,55 @@ const (
 //go:embed prochelper
 var helper []byte
 
+// constraints is the default resource limit profile applied to every
+// process unless it is overridden using WithLimits.
+//
 //go:embed constraints.json
 var constraints []byte
 
-func deployHelper() (tempdir, helperpath string, err error) {
-	// create a temp directory for use with this sandbox
-	tempdir, err = os.MkdirTemp(os.TempDir(), sandboxPattern)
+// defaultLimits decodes the embedded default resource limit profile.
+func defaultLimits() (Limits, error) {
+	l := Limits{}
+
+	err := json.Unmarshal(constraints, &l)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	return l, l.Validate()
+}
+
+// deployHelper writes the helper binary to the directory of the sandbox.
+// When no directory is provided a new temp directory is created.
+func deployHelper(dir string) (tempdir, helperpath string, err error) {
+	tempdir = dir
+	if tempdir == "" {
+		// create a temp directory for use with this sandbox
+		tempdir, err = os.MkdirTemp(os.TempDir(), sandboxPattern)
+	} else {
+		err = os.MkdirAll(tempdir, 0700)
+	}
 	if err != nil {
 		return "", "", err
 	}
 
+	helperpath = filepath.Join(tempdir, helperCmd)
+
 	// Write the helper binary to the temp directory
-	// with executable permissions.
+	// with executable permissions for the unprivileged
+	// users the helper is isolated as. The binary is replaced
+	// using a rename since the helpers of re-adopted
+	// processes may still be executing the existing binary.
 	// nolint:gosec
-	err = os.WriteFile(filepath.Join(tempdir, helperCmd), helper, 0700)
+	err = os.WriteFile(helperpath+".tmp", helper, 0755)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Write the resource limits to the temp directory
-	err = os.WriteFile(filepath.Join(tempdir, constraintsConfig), constraints, 0600)
+	err = os.Rename(helperpath+".tmp", helperpath)
 	if err != nil {
 		return "", "", err
 	}
 
-	helperpath = filepath.Join(tempdir, helperCmd)
-
 	return tempdir, helperpath, nil
 }
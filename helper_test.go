@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"os"
-	"path/filepath"
 	"testing"
 )
 
 func Test_deployHelper(t *testing.T) {
-	tempdir, helperpath, err := deployHelper()
+	tempdir, helperpath, err := deployHelper("")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -38,16 +37,19 @@ func Test_deployHelper(t *testing.T) {
 	if !bytes.Equal(deployed[:], embedded[:]) {
 		t.Fatalf("expected sha256 to be the same")
 	}
+}
 
-	// verify constraint deployment
-	data, err = os.ReadFile(filepath.Join(tempdir, constraintsConfig))
+func Test_defaultLimits(t *testing.T) {
+	limits, err := defaultLimits()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	deployed = sha256.Sum256(data)
-	embedded = sha256.Sum256(constraints)
-	if !bytes.Equal(deployed[:], embedded[:]) {
-		t.Fatalf("expected sha256 to be the same")
+	if limits.Memory == 0 {
+		t.Fatal("expected default memory limit to be set")
+	}
+
+	if limits.CPUQuota == 0 {
+		t.Fatal("expected default cpu quota to be set")
 	}
 }
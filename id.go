@@ -0,0 +1,22 @@
+package sandbox
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newID returns a random (version 4) UUID in its canonical string form
+// which is used to identify a process within the sandbox.
+func newID() (string, error) {
+	var u [16]byte
+	_, err := rand.Read(u[:])
+	if err != nil {
+		return "", err
+	}
+
+	// Set the version (4) and the variant (RFC 4122) bits.
+	u[6] = (u[6] & 0x0f) | 0x40 //nolint:gomnd // UUID version bits
+	u[8] = (u[8] & 0x3f) | 0x80 //nolint:gomnd // UUID variant bits
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
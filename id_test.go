@@ -0,0 +1,30 @@
+package sandbox
+
+import (
+	"regexp"
+	"testing"
+)
+
+func Test_newID(t *testing.T) {
+	format := regexp.MustCompile(
+		`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+	)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id, err := newID()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !format.MatchString(id) {
+			t.Fatalf("expected a version 4 UUID, got %q", id)
+		}
+
+		if seen[id] {
+			t.Fatalf("duplicate id %q", id)
+		}
+
+		seen[id] = true
+	}
+}
@@ -0,0 +1,368 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNotFound is returned by Open when the archive holds no bundle for
+// the process.
+var ErrNotFound = errors.New("bundle not found")
+
+const (
+	// suffix ends the name of every bundle, which is named after the
+	// ID of its process.
+	suffix = ".tar.gz"
+
+	// statusEntry and outputEntry are the entries of a bundle holding
+	// the final status and the framed output of the process.
+	statusEntry = "status.json"
+	outputEntry = "output"
+
+	// offsetRecord is the PAX record of an output entry holding the
+	// offset of its data within the output file.
+	offsetRecord = "SANDBOX.offset"
+
+	// seekData and seekHole are the whences seeking to the next data
+	// and the next hole of a sparse file, see lseek(2).
+	seekData = 3
+	seekHole = 4
+)
+
+// path returns the path of the bundle of the process `id` in `dir`. IDs
+// which are not a plain file name are rejected so a bundle is never read
+// or written outside of the archive.
+func path(dir, id string) (string, error) {
+	if id == "" || filepath.Base(id) != id || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	return filepath.Join(dir, id+suffix), nil
+}
+
+// Write writes the bundle of the process `id` holding its final `status`
+// and the output file at `output` to the archive `dir`. The bundle is
+// written to a temporary file which is renamed, so a partial bundle is
+// never read. A missing output file is archived as empty output.
+func Write(dir, id string, status []byte, output string) error {
+	dst, err := path(dir, id)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+id+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(zw)
+
+	err = writeEntry(tw, statusEntry, int64(len(status)), bytes.NewReader(status))
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Open(output)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		err = writeEntry(tw, outputEntry, 0, bytes.NewReader(nil))
+	case err == nil:
+		err = writeFile(tw, out)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = tw.Close()
+	if err == nil {
+		err = zw.Close()
+	}
+
+	if err == nil {
+		err = tmp.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+// writeFile writes the output file `f` as output entries and closes it.
+// Every range of data of the file is written as an entry holding its
+// offset, so the ranges a ring discarded from the file are neither
+// archived nor restored. Files which cannot be searched for holes are
+// written as a single range.
+func writeFile(tw *tar.Writer, f *os.File) error {
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := info.Size()
+	for start := int64(0); start < size; {
+		start, err = f.Seek(start, seekData)
+		if errors.Is(err, syscall.ENXIO) {
+			// The rest of the file is a hole.
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		end, err := f.Seek(start, seekHole)
+		if err != nil {
+			return err
+		}
+
+		if end > size {
+			end = size
+		}
+
+		err = writeRange(tw, io.NewSectionReader(f, start, end-start), start)
+		if err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// writeRange writes the range of the output file at `offset` as an output
+// entry.
+func writeRange(tw *tar.Writer, r *io.SectionReader, offset int64) error {
+	return writeHeader(tw, &tar.Header{
+		Name:       outputEntry,
+		Size:       r.Size(),
+		PAXRecords: map[string]string{offsetRecord: strconv.FormatInt(offset, 10)},
+	}, r)
+}
+
+// writeEntry writes `size` bytes of `r` as the entry `name`.
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	return writeHeader(tw, &tar.Header{Name: name, Size: size}, r)
+}
+
+// writeHeader writes the regular file entry `hdr` holding the data of `r`.
+func writeHeader(tw *tar.Writer, hdr *tar.Header, r io.Reader) error {
+	hdr.Mode = 0600
+	hdr.ModTime = time.Now()
+	hdr.Typeflag = tar.TypeReg
+
+	err := tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tw, r, hdr.Size)
+	return err
+}
+
+// open opens the bundle of the process `id` in the archive `dir` for
+// reading its entries.
+func open(dir, id string) (*tar.Reader, io.Closer, error) {
+	src, err := path(dir, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return tar.NewReader(zr), f, nil
+}
+
+// Status reads only the status of the bundle of the process `id` from the
+// archive `dir`, without decompressing its output.
+func Status(dir, id string) ([]byte, error) {
+	tr, f, err := open(dir, id)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: %s has no status", ErrNotFound, id)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Name == statusEntry {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// Open reads the bundle of the process `id` from the archive `dir`. The
+// output is decompressed into an unlinked temporary file of the archive,
+// so it can be read from any offset and is removed once it is closed. The
+// ranges of the output which were not archived are left as holes of the
+// file, so they take up no space.
+func Open(dir, id string) (status []byte, output *os.File, err error) {
+	tr, f, err := open(dir, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	output, err = os.CreateTemp(dir, "."+id+"-output-*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The file is removed while it is open so it is released
+	// once it is closed.
+	_ = os.Remove(output.Name())
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil {
+			switch hdr.Name {
+			case statusEntry:
+				status, err = io.ReadAll(tr)
+			case outputEntry:
+				err = readRange(output, tr, hdr)
+			}
+		}
+
+		if err != nil {
+			output.Close()
+			return nil, nil, err
+		}
+	}
+
+	if status == nil {
+		output.Close()
+		return nil, nil, fmt.Errorf("%w: %s has no status", ErrNotFound, id)
+	}
+
+	_, err = output.Seek(0, io.SeekStart)
+	if err != nil {
+		output.Close()
+		return nil, nil, err
+	}
+
+	return status, output, nil
+}
+
+// readRange reads the output entry `hdr` into the output file at the
+// offset of the entry.
+func readRange(output *os.File, tr *tar.Reader, hdr *tar.Header) error {
+	offset := int64(0)
+	if v, ok := hdr.PAXRecords[offsetRecord]; ok {
+		var err error
+		offset, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			return fmt.Errorf("invalid output offset %q", v)
+		}
+	}
+
+	_, err := output.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(output, tr)
+	return err
+}
+
+// Prune removes the bundles of the archive `dir` which were written more
+// than `age` ago, and then the oldest bundles until the bundles take up
+// at most `size` bytes. Zero values do not bound the archive.
+func Prune(dir string, age time.Duration, size int64) error {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+	if err != nil {
+		return err
+	}
+
+	type bundle struct {
+		path    string
+		size    int64
+		written time.Time
+	}
+
+	var bundles []bundle
+	var total int64
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+
+		if age > 0 && time.Since(info.ModTime()) > age {
+			err = os.Remove(m)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			continue
+		}
+
+		bundles = append(bundles, bundle{m, info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	if size <= 0 {
+		return nil
+	}
+
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].written.Before(bundles[j].written)
+	})
+
+	for _, b := range bundles {
+		if total <= size {
+			break
+		}
+
+		err = os.Remove(b.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		total -= b.size
+	}
+
+	return nil
+}
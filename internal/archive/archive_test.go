@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_Write_Open(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "output")
+
+	err := os.WriteFile(output, []byte("framed output"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Write(dir, "job", []byte(`{"Exited":true}`), output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, f, err := Open(dir, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(status) != `{"Exited":true}` || string(data) != "framed output" {
+		t.Fatalf("unexpected bundle %q, %q", status, data)
+	}
+
+	// Only the bundle is kept in the archive.
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(matches) != 1 {
+		t.Fatalf("expected a single bundle, got %v", matches)
+	}
+
+	for _, id := range []string{"missing", "../job", ".", ""} {
+		_, _, err = Open(dir, id)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound for %q, got %v", id, err)
+		}
+	}
+
+	// Missing output is archived as empty output.
+	err = Write(dir, "empty", []byte("{}"), filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, f, err = Open(dir, "empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err != nil || info.Size() != 0 {
+		t.Fatalf("expected empty output, got %v, %v", info, err)
+	}
+}
+
+func Test_Write_Open_Sparse(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "output")
+
+	// The output is a hole of 64MiB between its head and its tail,
+	// like the range a ring discarded.
+	const hole = 64 << 20
+	f, err := os.Create(output)
+	if err == nil {
+		_, err = f.WriteAt([]byte("head"), 0)
+	}
+
+	if err == nil {
+		_, err = f.WriteAt([]byte("tail"), hole)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	err = Write(dir, "job", []byte("{}"), output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "job"+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Size() > 64<<10 {
+		t.Fatalf("expected the hole not to be archived, got %d bytes", info.Size())
+	}
+
+	status, err := Status(dir, "job")
+	if err != nil || string(status) != "{}" {
+		t.Fatalf("unexpected status %q, %v", status, err)
+	}
+
+	_, f, err = Open(dir, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	head, tail := make([]byte, 4), make([]byte, 4)
+	_, err = f.ReadAt(head, 0)
+	if err == nil {
+		_, err = f.ReadAt(tail, hole)
+	}
+
+	if err != nil || string(head) != "head" || string(tail) != "tail" {
+		t.Fatalf("unexpected output %q, %q, %v", head, tail, err)
+	}
+
+	info, err = f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hole is restored without allocating it.
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Blocks*512 >= hole {
+		t.Fatalf("expected a sparse output, got %d blocks", st.Blocks)
+	}
+}
+
+func Test_Prune(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(t.TempDir(), "output")
+
+	err := os.WriteFile(output, []byte("output"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundles are written an hour apart, the first one is the
+	// oldest.
+	ids := []string{"expired", "old", "new", "newest"}
+	var size int64
+	for i, id := range ids {
+		err = Write(dir, id, []byte("{}"), output)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		written := time.Now().Add(-time.Hour * time.Duration(len(ids)-i))
+		err = os.Chtimes(filepath.Join(dir, id+suffix), written, written)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, id+suffix))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		size = info.Size()
+	}
+
+	err = Prune(dir, time.Hour*3+time.Minute, size*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, id := range ids {
+		_, err := os.Stat(filepath.Join(dir, id+suffix))
+		if kept := i >= 2; kept != (err == nil) {
+			t.Fatalf("expected %s to be kept: %v, got %v", id, kept, err)
+		}
+	}
+}
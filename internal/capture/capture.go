@@ -0,0 +1,333 @@
+package capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Stream identifies the output stream a record was captured from.
+type Stream uint8
+
+const (
+	// Stdout tags records captured from the standard output.
+	Stdout Stream = 1 << iota
+
+	// Stderr tags records captured from the standard error.
+	Stderr
+)
+
+const (
+	// timedStream tags records which follow the header with the time
+	// they were captured at. Records written before records were
+	// timestamped are decoded without a time.
+	timedStream Stream = 1 << 6
+
+	// headerSize is the size of the record header: one byte for the
+	// stream followed by the big-endian length of the data. The
+	// header of a timestamped record is followed by the big-endian
+	// capture time in nanoseconds since the Unix epoch, making up
+	// timedHeaderSize.
+	headerSize      = 5
+	timedHeaderSize = headerSize + 8
+
+	// maxRecord is the largest amount of data stored in a single record.
+	// Larger writes are split into multiple records.
+	maxRecord = 1 << 16
+
+	// readSize is the amount of data requested from the underlying
+	// reader when a complete record is not buffered.
+	readSize = 32 * 1024
+)
+
+var (
+	// ErrCorrupt is returned when the record framing cannot be decoded.
+	ErrCorrupt = errors.New("corrupt output record")
+
+	// ErrNotSeekable is returned by SeekRecord when the underlying reader
+	// cannot seek.
+	ErrNotSeekable = errors.New("output is not seekable")
+)
+
+// Record is a single chunk of output tagged with the stream it was
+// captured from and the time it was captured at. Offset is the offset of
+// the record in the output.
+type Record struct {
+	Stream Stream
+	Data   []byte
+	Time   time.Time
+	Offset int64
+}
+
+// End returns the offset of the output following the record.
+func (r Record) End() int64 {
+	size := headerSize
+	if !r.Time.IsZero() {
+		size = timedHeaderSize
+	}
+
+	return r.Offset + int64(size+len(r.Data))
+}
+
+// Writer serializes the output of multiple streams into framed records
+// on a single underlying writer.
+type Writer struct {
+	w  io.Writer
+	mu sync.Mutex
+
+	// size is the offset of the end of the records, the limit bounds
+	// the records retained by a Writer created by NewLimitedWriter.
+	size  int64
+	limit *limit
+}
+
+// NewWriter creates a Writer which appends framed records to `w`.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Stream returns an io.Writer which tags everything written to it with
+// the stream `s`.
+func (w *Writer) Stream(s Stream) io.Writer {
+	return streamWriter{w, s}
+}
+
+// write frames `p` into one or more records for stream `s` stamped with
+// the current time. Each record is written with a single call to the
+// underlying writer so concurrent streams are never interleaved inside of
+// a record.
+//
+// Output beyond the limit of a limited Writer is discarded and reported
+// as written so the writes of the command are not interrupted.
+func (w *Writer) write(s Stream, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	written := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > maxRecord {
+			size = maxRecord
+		}
+
+		if w.limit != nil {
+			size = w.limit.recordSize(w.size, size)
+			if size == 0 {
+				return written + len(p), w.truncate(s, now)
+			}
+		}
+
+		err := w.record(s, p[:size], now)
+		if err != nil {
+			return written, err
+		}
+
+		written += size
+		p = p[size:]
+
+		if w.limit != nil && w.limit.ring {
+			err = w.discard(s, now)
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// record writes `p` as a single record of stream `s`. The record is
+// timestamped with `t` unless it is the zero time.
+func (w *Writer) record(s Stream, p []byte, t time.Time) error {
+	size := headerSize
+	if !t.IsZero() {
+		size = timedHeaderSize
+		s |= timedStream
+	}
+
+	buf := make([]byte, size+len(p))
+	buf[0] = byte(s)
+	binary.BigEndian.PutUint32(buf[1:headerSize], uint32(len(p)))
+	if !t.IsZero() {
+		binary.BigEndian.PutUint64(buf[headerSize:size], uint64(t.UnixNano()))
+	}
+
+	copy(buf[size:], p)
+
+	n, err := w.w.Write(buf)
+	w.size += int64(n)
+
+	return err
+}
+
+type streamWriter struct {
+	w *Writer
+	s Stream
+}
+
+func (s streamWriter) Write(p []byte) (int, error) {
+	return s.w.write(s.s, p)
+}
+
+// Reader decodes the framed records written by a Writer.
+type Reader struct {
+	r    io.Reader
+	buf  []byte
+	read []byte
+
+	// pos is the offset of the end of the buffer in the output, and
+	// ring is the offset of the start of the ring of the output, see
+	// NewLimitedWriter, or -1 when the output is not a ring.
+	pos  int64
+	ring int64
+}
+
+// NewReader creates a Reader which decodes the records from `r`. The
+// records discarded by a ring are skipped when `r` is an io.ReaderAt and
+// an io.Seeker such as an *os.File.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:    r,
+		read: make([]byte, readSize),
+		ring: -1,
+	}
+}
+
+// Next returns the next record. When the underlying reader has no more
+// data available, without reaching the end of the output, Next returns
+// an empty record and a nil error so the caller can retry. io.EOF is
+// returned once the end of the output is reached.
+func (r *Reader) Next() (Record, error) {
+	for {
+		rec, ok, err := r.decode()
+		if err != nil || ok {
+			return rec, err
+		}
+
+		n, err := r.r.Read(r.read)
+		r.buf = append(r.buf, r.read[:n]...)
+		r.pos += int64(n)
+
+		if n > 0 {
+			skipErr := r.skipDiscarded()
+			if skipErr != nil {
+				return Record{}, skipErr
+			}
+		}
+
+		if err == io.EOF {
+			if n > 0 {
+				continue
+			}
+
+			if len(r.buf) > 0 {
+				return Record{}, io.ErrUnexpectedEOF
+			}
+
+			return Record{}, io.EOF
+		}
+
+		if err != nil {
+			return Record{}, err
+		}
+
+		if n == 0 {
+			return Record{}, nil
+		}
+	}
+}
+
+// SeekRecord positions the reader at `offset`, which is either zero or the
+// offset or end of a record returned by Next. Offsets of records which
+// were discarded by a ring are advanced to the oldest record retained.
+// The underlying reader must be an io.ReaderAt and an io.Seeker.
+func (r *Reader) SeekRecord(offset int64) error {
+	ra, ok := r.r.(io.ReaderAt)
+	seeker, ok2 := r.r.(io.Seeker)
+	if !ok || !ok2 {
+		return ErrNotSeekable
+	}
+
+	// A ring starts with the ring record which is read to skip the
+	// records discarded by the ring.
+	head := make([]byte, ringRecord)
+	n, err := ra.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	r.ring = -1
+	if n == ringRecord && Stream(head[0]) == ringStream {
+		r.ring = headerSize
+		if offset < ringRecord {
+			offset = ringRecord
+		}
+	}
+
+	_, err = seeker.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	r.buf = r.buf[:0]
+	r.pos = offset
+
+	return r.skipDiscarded()
+}
+
+// decode pops a complete record from the buffer if one is available.
+func (r *Reader) decode() (Record, bool, error) {
+	for len(r.buf) >= headerSize && Stream(r.buf[0]) == ringStream {
+		ok, err := r.decodeRing()
+		if err != nil || !ok {
+			return Record{}, false, err
+		}
+	}
+
+	if len(r.buf) < headerSize {
+		return Record{}, false, nil
+	}
+
+	s := Stream(r.buf[0]) &^ timedStream
+	size := int(binary.BigEndian.Uint32(r.buf[1:headerSize]))
+
+	if (s != Stdout && s != Stderr) || size > maxRecord {
+		return Record{}, false, fmt.Errorf(
+			"%w: stream %d with %d bytes",
+			ErrCorrupt,
+			s,
+			size,
+		)
+	}
+
+	header := headerSize
+	if Stream(r.buf[0])&timedStream != 0 {
+		header = timedHeaderSize
+	}
+
+	if len(r.buf) < header+size {
+		return Record{}, false, nil
+	}
+
+	rec := Record{
+		Stream: s,
+		Data:   make([]byte, size),
+		Offset: r.pos - int64(len(r.buf)),
+	}
+
+	if header == timedHeaderSize {
+		rec.Time = time.Unix(0, int64(binary.BigEndian.Uint64(r.buf[headerSize:header])))
+	}
+
+	copy(rec.Data, r.buf[header:header+size])
+
+	// Shift the remaining data to the front of the buffer so
+	// the buffer does not grow with the total output size.
+	r.buf = append(r.buf[:0], r.buf[header+size:]...)
+
+	return rec, true, nil
+}
@@ -0,0 +1,222 @@
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_Writer_Reader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+
+	writes := []Record{
+		{Stream: Stdout, Data: []byte("hello ")},
+		{Stream: Stderr, Data: []byte("oops\n")},
+		{Stream: Stdout, Data: []byte("world\n")},
+	}
+
+	for _, rec := range writes {
+		_, err := w.Stream(rec.Stream).Write(rec.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewReader(buf)
+	for _, expected := range writes {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Stream != expected.Stream {
+			t.Fatalf("expected stream %d, got %d", expected.Stream, rec.Stream)
+		}
+
+		if !bytes.Equal(rec.Data, expected.Data) {
+			t.Fatalf("expected %q, got %q", expected.Data, rec.Data)
+		}
+	}
+
+	_, err := r.Next()
+	if err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func Test_Writer_split(t *testing.T) {
+	buf := &bytes.Buffer{}
+	data := bytes.Repeat([]byte("a"), maxRecord+10)
+
+	n, err := NewWriter(buf).Stream(Stdout).Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(data) {
+		t.Fatalf("expected %d bytes written, got %d", len(data), n)
+	}
+
+	r := NewReader(buf)
+	var out []byte
+	records := 0
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records++
+		out = append(out, rec.Data...)
+	}
+
+	if records != 2 {
+		t.Fatalf("expected 2 records, got %d", records)
+	}
+
+	if !bytes.Equal(out, data) {
+		t.Fatal("expected split records to reassemble the data")
+	}
+}
+
+// pending is a reader which returns no data without an error once the
+// buffered data is consumed, like a file which is still being written.
+type pending struct {
+	*bytes.Buffer
+}
+
+func (p pending) Read(b []byte) (int, error) {
+	n, err := p.Buffer.Read(b)
+	if err == io.EOF {
+		err = nil
+	}
+
+	return n, err
+}
+
+func Test_Reader_incomplete(t *testing.T) {
+	full := &bytes.Buffer{}
+	_, err := NewWriter(full).Stream(Stderr).Write([]byte("partial"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := pending{&bytes.Buffer{}}
+	src.Write(full.Bytes()[:headerSize+3])
+
+	r := NewReader(src)
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rec.Data) != 0 {
+		t.Fatalf("expected empty record, got %q", rec.Data)
+	}
+
+	src.Write(full.Bytes()[headerSize+3:])
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Stream != Stderr || string(rec.Data) != "partial" {
+		t.Fatalf("unexpected record %d: %q", rec.Stream, rec.Data)
+	}
+}
+
+func Test_Reader_corrupt(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte{9, 0, 0, 0, 1, 'a'}))
+
+	_, err := r.Next()
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
+
+func Test_Reader_SeekRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	for _, data := range []string{"one", "two", "three"} {
+		_, err := w.Stream(Stdout).Write([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewReader(bytes.NewReader(buf.Bytes()))
+	first, err := r.Next()
+	if err != nil || first.Offset != 0 {
+		t.Fatalf("expected the first record at offset 0, got %d, %v", first.Offset, err)
+	}
+
+	second, err := r.Next()
+	if err != nil || second.Offset != first.End() {
+		t.Fatalf("expected the second record at offset %d, got %d, %v", first.End(), second.Offset, err)
+	}
+
+	// Seeking to the end of a record resumes reading at the next record.
+	err = r.SeekRecord(first.End())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := r.Next()
+	if err != nil || string(rec.Data) != "two" || rec.Offset != second.Offset {
+		t.Fatalf("expected the second record, got %q at %d, %v", rec.Data, rec.Offset, err)
+	}
+
+	err = r.SeekRecord(int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.Next(); err != io.EOF {
+		t.Fatalf("expected EOF at the end of the output, got %v", err)
+	}
+
+	if err = NewReader(buf).SeekRecord(0); !errors.Is(err, ErrNotSeekable) {
+		t.Fatalf("expected ErrNotSeekable, got %v", err)
+	}
+}
+
+func Test_Reader_time(t *testing.T) {
+	buf := &bytes.Buffer{}
+	before := time.Now()
+	_, err := NewWriter(buf).Stream(Stdout).Write([]byte("timed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := time.Now()
+
+	// Records written before records were timestamped have no time.
+	buf.Write([]byte{byte(Stderr), 0, 0, 0, 6, 'u', 'n', 't', 'i', 'm', 'e'})
+
+	r := NewReader(buf)
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Time.Before(before) || rec.Time.After(after) || rec.End() != timedHeaderSize+5 {
+		t.Fatalf("expected a timestamped record, got %+v", rec)
+	}
+
+	rec, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rec.Time.IsZero() || rec.Stream != Stderr || string(rec.Data) != "untime" || rec.End() != rec.Offset+headerSize+6 {
+		t.Fatalf("expected a record without a time, got %+v", rec)
+	}
+}
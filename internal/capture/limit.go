@@ -0,0 +1,241 @@
+package capture
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// TruncatedMarker is appended to the output of a Writer which truncates its
+// output once the limit is reached. The marker is not counted against the
+// limit.
+const TruncatedMarker = "\n[output truncated]\n"
+
+const (
+	// ringStream tags the record at the start of a ring which holds the
+	// offset of the oldest record retained by the ring. The record is
+	// not returned by a Reader.
+	ringStream Stream = 1 << 7
+
+	// ringSize is the size of the data and ringRecord the size of the
+	// record of a ring.
+	ringSize   = 8
+	ringRecord = headerSize + ringSize
+)
+
+const (
+	// fallocKeepSize and fallocPunchHole are the flags of fallocate(2)
+	// which deallocate a range of a file without changing its size.
+	fallocKeepSize  = 0x01
+	fallocPunchHole = 0x02
+)
+
+// limit bounds the records retained by a Writer. The Writer either stops
+// writing records once the limit is reached, or keeps a ring of the most
+// recent records by discarding the oldest records from the file.
+type limit struct {
+	bytes int64
+	ring  bool
+	file  *os.File
+
+	// ends are the offsets of the end of the records of a ring which
+	// were not discarded yet, start is the offset of the oldest
+	// record which is retained and the offset of the ring record is
+	// at.
+	ends  []int64
+	start int64
+	at    int64
+
+	truncated bool
+}
+
+// NewLimitedWriter creates a Writer which appends framed records to the
+// file `f` and retains at most `bytes` bytes of records, including the
+// framing. Once the limit is reached the output is either truncated and
+// TruncatedMarker is appended, or, for a `ring`, the oldest records are
+// discarded to make room for the new records.
+//
+// The records discarded by a ring are deallocated from the file without
+// changing the offsets of the remaining records. The ring starts with a
+// record holding the offset of the oldest record retained, which is
+// updated before records are discarded, so a Reader skips the records
+// discarded while they are read.
+func NewLimitedWriter(f *os.File, bytes int64, ring bool) *Writer {
+	// The records are appended to the current offset of the file.
+	offset, _ := f.Seek(0, io.SeekCurrent)
+
+	w := &Writer{
+		w:    f,
+		size: offset,
+		limit: &limit{
+			bytes: bytes,
+			ring:  ring,
+			file:  f,
+			start: offset,
+		},
+	}
+
+	if ring {
+		w.limit.at = offset + headerSize
+		w.limit.start = offset + ringRecord
+		w.limit.bytes -= ringRecord
+
+		// The record is written once the file is written to so
+		// a failure is reported by the first write.
+		_ = w.record(ringStream, ringOffset(w.limit.start), time.Time{})
+	}
+
+	return w
+}
+
+// ringOffset encodes the offset of the oldest record of a ring.
+func ringOffset(offset int64) []byte {
+	data := make([]byte, ringSize)
+	binary.BigEndian.PutUint64(data, uint64(offset))
+
+	return data
+}
+
+// recordSize returns the size of the data of the next record of at most
+// `size` bytes when the records end at `end`. Zero is returned when the
+// output has to be truncated.
+func (l *limit) recordSize(end int64, size int) int {
+	if l.truncated {
+		return 0
+	}
+
+	// A single record of a ring always fits within the limit so
+	// the most recent record is retained.
+	room := l.bytes - timedHeaderSize
+	if !l.ring {
+		room -= end - l.start
+	}
+
+	if room <= 0 && l.ring {
+		room = 1
+	}
+
+	if room <= 0 {
+		return 0
+	}
+
+	if int64(size) > room {
+		return int(room)
+	}
+
+	return size
+}
+
+// truncate appends the marker of stream `s` captured at `t` once and stops
+// writing records.
+func (w *Writer) truncate(s Stream, t time.Time) error {
+	if w.limit.truncated {
+		return nil
+	}
+
+	w.limit.truncated = true
+	return w.record(s, []byte(TruncatedMarker), t)
+}
+
+// discard discards the oldest records of a ring until the records fit
+// within the limit. The output is truncated with the marker of stream `s`
+// captured at `t` instead when the records cannot be discarded from the
+// file.
+func (w *Writer) discard(s Stream, t time.Time) error {
+	l := w.limit
+	l.ends = append(l.ends, w.size)
+
+	start := l.start
+	for w.size-start > l.bytes && len(l.ends) > 1 {
+		start = l.ends[0]
+		l.ends = l.ends[1:]
+	}
+
+	if start == l.start {
+		return nil
+	}
+
+	// The offset is updated before the records are discarded so
+	// readers do not read the discarded records.
+	_, err := l.file.WriteAt(ringOffset(start), l.at)
+	if err == nil {
+		err = syscall.Fallocate(
+			int(l.file.Fd()),
+			fallocKeepSize|fallocPunchHole,
+			l.start,
+			start-l.start,
+		)
+	}
+
+	if err != nil {
+		l.ring = false
+		return w.truncate(s, t)
+	}
+
+	l.start = start
+	return nil
+}
+
+// decodeRing pops the ring record from the buffer once it is complete and
+// skips the records the ring discarded.
+func (r *Reader) decodeRing() (bool, error) {
+	if len(r.buf) < ringRecord {
+		return false, nil
+	}
+
+	if size := binary.BigEndian.Uint32(r.buf[1:headerSize]); size != ringSize {
+		return false, fmt.Errorf("%w: ring with %d bytes", ErrCorrupt, size)
+	}
+
+	r.ring = r.pos - int64(len(r.buf)) + headerSize
+	r.buf = append(r.buf[:0], r.buf[ringRecord:]...)
+
+	return true, r.skipDiscarded()
+}
+
+// skipDiscarded drops the records of a ring which were discarded by the
+// writer from the buffer, and seeks past them when they were not read
+// yet. The records in the buffer at or after the oldest record of the
+// ring were read before they could be discarded, since the offset of the
+// oldest record is updated before records are discarded.
+func (r *Reader) skipDiscarded() error {
+	ra, ok := r.r.(io.ReaderAt)
+	if r.ring < 0 || !ok {
+		return nil
+	}
+
+	data := make([]byte, ringSize)
+	_, err := ra.ReadAt(data, r.ring)
+	if err != nil {
+		return err
+	}
+
+	start := int64(binary.BigEndian.Uint64(data))
+	bufStart := r.pos - int64(len(r.buf))
+
+	switch {
+	case start <= bufStart:
+		return nil
+	case start <= r.pos:
+		r.buf = append(r.buf[:0], r.buf[start-bufStart:]...)
+		return nil
+	}
+
+	seeker, ok := r.r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("%w: output of the ring was discarded", ErrCorrupt)
+	}
+
+	_, err = seeker.Seek(start, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	r.buf = r.buf[:0]
+	r.pos = start
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// byteRecord is the size of a record of a single byte.
+const byteRecord = timedHeaderSize + 1
+
+// readAll decodes the data of every record of the file at `path`.
+func readAll(t *testing.T, path string) []byte {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var data []byte
+	r := NewReader(f)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return data
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data = append(data, rec.Data...)
+	}
+}
+
+func Test_NewLimitedWriter(t *testing.T) {
+	tests := map[string]struct {
+		ring     bool
+		bytes    int64
+		expected string
+	}{
+		"truncate": {false, 4 * byteRecord, "0123" + TruncatedMarker},
+		"ring":     {true, ringRecord + 4*byteRecord, "6789"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output")
+			f, err := os.Create(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			// Four records of a single byte fit within the limit.
+			w := NewLimitedWriter(f, test.bytes, test.ring).Stream(Stdout)
+			for _, b := range []byte("0123456789") {
+				n, err := w.Write([]byte{b})
+				if err != nil || n != 1 {
+					t.Fatalf("expected the write to succeed, got %d, %v", n, err)
+				}
+			}
+
+			if got := string(readAll(t, path)); got != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, got)
+			}
+
+			info, err := f.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.ring && info.Size() != ringRecord+10*byteRecord {
+				t.Fatalf("expected the ring to keep the offsets, got size %d", info.Size())
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "output")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Writes larger than the room left are split.
+	_, err = NewLimitedWriter(f, timedHeaderSize+15, false).Stream(Stderr).Write([]byte("0123456789abcdefghij"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readAll(t, path); !bytes.Equal(got, []byte("0123456789abcde"+TruncatedMarker)) {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func Test_Reader_ring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := NewLimitedWriter(f, ringRecord+4*byteRecord, true).Stream(Stdout)
+	write := func(data string) {
+		for _, b := range []byte(data) {
+			_, err := w.Write([]byte{b})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	rf, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	write("0123")
+
+	r := NewReader(rf)
+	rec, err := r.Next()
+	if err != nil || string(rec.Data) != "0" {
+		t.Fatalf("expected the first record, got %q, %v", rec.Data, err)
+	}
+
+	// The records buffered by the reader were read before they were
+	// discarded and are returned, while the records discarded before
+	// they were read are skipped.
+	write("456789")
+
+	var data []byte
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data = append(data, rec.Data...)
+	}
+
+	if string(data) != "1236789" {
+		t.Fatalf("expected the retained records, got %q", data)
+	}
+}
+
+func Test_Reader_SeekRecord_ring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := NewLimitedWriter(f, ringRecord+4*byteRecord, true).Stream(Stdout)
+	for _, b := range []byte("0123456789") {
+		_, err := w.Write([]byte{b})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Offsets of the discarded records are advanced to the oldest
+	// record retained by the ring.
+	for _, offset := range []int64{0, ringRecord + byteRecord} {
+		r := NewReader(f)
+		err = r.SeekRecord(offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec, err := r.Next()
+		if err != nil || string(rec.Data) != "6" || rec.Offset != ringRecord+6*byteRecord {
+			t.Fatalf("expected the oldest record from offset %d, got %q at %d, %v", offset, rec.Data, rec.Offset, err)
+		}
+	}
+}
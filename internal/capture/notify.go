@@ -0,0 +1,136 @@
+package capture
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// notifyInterval is the interval a Notifier broadcasts at when the file
+// cannot be watched, e.g. when the inotify watches of the user are
+// exhausted.
+const notifyInterval = time.Millisecond * 50
+
+// inotifyEvents is the size of the buffer the events of the watch are
+// read into. Only the arrival of events matters, so the buffer is sized
+// for several events without names.
+const inotifyEvents = 64 * syscall.SizeofInotifyEvent
+
+// Notifier broadcasts the growth of an output file written by another
+// process to the readers following the output. Readers wait on the
+// channel returned by Changed, which is closed once the file is written
+// to, instead of polling the file.
+type Notifier struct {
+	mu      sync.Mutex
+	changed chan struct{}
+	closed  bool
+
+	// watch is the inotify instance watching the file, or nil when
+	// the file is polled.
+	watch *os.File
+	done  chan struct{}
+}
+
+// NewNotifier creates a Notifier for the file at `path` which is watched
+// using inotify. The file is polled when it cannot be watched.
+func NewNotifier(path string) *Notifier {
+	n := &Notifier{
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err == nil {
+		_, err = syscall.InotifyAddWatch(fd, path, syscall.IN_MODIFY|syscall.IN_CLOSE_WRITE)
+		if err != nil {
+			_ = syscall.Close(fd)
+		}
+	}
+
+	if err != nil {
+		go n.poll()
+		return n
+	}
+
+	// The instance is non-blocking so reads wait in the runtime
+	// poller and are interrupted by Close.
+	n.watch = os.NewFile(uintptr(fd), "inotify")
+	go n.read()
+
+	return n
+}
+
+// Changed returns a channel which is closed once the file is written to
+// after Changed was called, or the Notifier is closed. The channel is
+// taken before the file is read so no write is missed between reaching
+// the end of the file and waiting.
+func (n *Notifier) Changed() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.changed
+}
+
+// Close stops watching the file and wakes every waiting reader.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+
+	n.closed = true
+	close(n.changed)
+	close(n.done)
+	n.mu.Unlock()
+
+	if n.watch != nil {
+		return n.watch.Close()
+	}
+
+	return nil
+}
+
+// broadcast wakes the readers waiting for the file to change. Once the
+// Notifier is closed the closed channel is kept, so readers never wait.
+func (n *Notifier) broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	close(n.changed)
+	n.changed = make(chan struct{})
+}
+
+// read broadcasts every batch of events of the watch until the Notifier
+// is closed.
+func (n *Notifier) read() {
+	buf := make([]byte, inotifyEvents)
+	for {
+		_, err := n.watch.Read(buf)
+		if err != nil {
+			return
+		}
+
+		n.broadcast()
+	}
+}
+
+// poll broadcasts at every interval until the Notifier is closed.
+func (n *Notifier) poll() {
+	ticker := time.NewTicker(notifyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case <-ticker.C:
+			n.broadcast()
+		}
+	}
+}
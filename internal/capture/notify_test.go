@@ -0,0 +1,58 @@
+package capture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Notifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	n := NewNotifier(path)
+	changed := n.Changed()
+
+	select {
+	case <-changed:
+		t.Fatal("expected no change before the file is written to")
+	case <-time.After(notifyInterval * 2):
+	}
+
+	_, err = NewWriter(f).Stream(Stdout).Write([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the write to be broadcast")
+	}
+
+	err = n.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-n.Changed():
+	default:
+		t.Fatal("expected readers of a closed notifier not to wait")
+	}
+
+	// Files which cannot be watched are polled.
+	n = NewNotifier(filepath.Join(t.TempDir(), "missing"))
+	defer n.Close()
+
+	select {
+	case <-n.Changed():
+	case <-time.After(time.Second):
+		t.Fatal("expected the missing file to be polled")
+	}
+}
@@ -5,15 +5,33 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 )
 
-func LimitResources(parent string, config []byte) error {
-	cg, err := LoadCGroups(parent, config)
+// LimitResources creates the cgroup `name` under the `parent` cgroup with
+// the constraints in `limits` and adds the current process to it. The
+// limits are translated for the hierarchy mounted on the host so that
+// processes get the same limits on either hierarchy.
+func LimitResources(parent, name string, limits Limits) error {
+	err := limits.Validate()
 	if err != nil {
 		return err
 	}
 
+	if Detect() == V2 {
+		u := limits.Unified()
+		u.parentFolder = parent
+		u.name = name
+		u.procID = os.Getpid()
+
+		return u.Write()
+	}
+
+	cg := limits.CGroups()
+	cg.account()
+	cg.parentFolder = parent
+	cg.name = name
+	cg.procID = os.Getpid()
+
 	return cg.Write()
 }
 
@@ -27,6 +45,7 @@ type CGroups struct {
 
 	procID       int    `json:"-"`
 	parentFolder string `json:"-"`
+	name         string `json:"-"`
 }
 
 // CGroupFiles represents a set of files in a cgroup folder.
@@ -64,7 +83,11 @@ type CGroupFiles struct {
 }
 */
 func LoadCGroups(parentFolder string, config []byte) (CGroups, error) {
-	c := CGroups{}
+	c := CGroups{
+		parentFolder: parentFolder,
+		procID:       os.Getpid(),
+		name:         strconv.Itoa(os.Getpid()),
+	}
 
 	err := json.Unmarshal(config, &c)
 	if err != nil {
@@ -83,7 +106,7 @@ func (c CGroups) Write() error {
 			cgroupPath,
 			cgRoot,
 			c.parentFolder,
-			strconv.Itoa(c.procID),
+			c.name,
 		)
 
 		err := os.MkdirAll(cgPath, 0755)
@@ -91,15 +114,19 @@ func (c CGroups) Write() error {
 			return err
 		}
 
-		// Write values to the cgroup files for this group.
+		// Write values to the cgroup files for this group. Each value
+		// is written separately since the kernel only parses a single
+		// entry per write for files like `blkio.throttle.*_device`.
 		for file, values := range files.Files {
-			err = os.WriteFile(
-				filepath.Join(cgPath, file),
-				[]byte(strings.Join(values, "\n")),
-				0600,
-			)
-			if err != nil {
-				return err
+			for _, value := range values {
+				err = os.WriteFile(
+					filepath.Join(cgPath, file),
+					[]byte(value),
+					0600,
+				)
+				if err != nil {
+					return err
+				}
 			}
 		}
 
@@ -127,15 +154,80 @@ func (c CGroups) Write() error {
 	return nil
 }
 
+// account adds the folders of the accounted controllers which are mounted
+// on the host, so the usage of the processes is accounted by every
+// controller.
+func (c CGroups) account() {
+	for _, folder := range accountedV1 {
+		if _, ok := c.Folders[folder]; ok {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(cgroupPath, folder)); err != nil {
+			continue
+		}
+
+		c.Folders[folder] = CGroupFiles{Files: map[string][]string{}}
+	}
+}
+
 // Clean attempts to cleanup the cgroup folders created by `c`.
-func (c CGroups) Clean(parent string) {
+func (c CGroups) Clean() {
 	for cgRoot := range c.Folders {
-		cgPath := filepath.Join(cgroupPath, cgRoot, c.parentFolder)
+		removeTree(filepath.Join(cgroupPath, cgRoot, c.parentFolder, c.name))
+	}
+}
+
+// Remove attempts to remove the cgroup `name` under the `parent` cgroup,
+// along with the cgroups below it, from the hierarchy mounted on the host.
+// An empty `name` removes the `parent` cgroup. The cgroup is removed from
+// every controller of the legacy hierarchy since the processes of a job
+// are added to controllers which do not limit them, see accountedV1.
+func Remove(parent, name string) {
+	if parent == "" {
+		return
+	}
 
-		_ = os.RemoveAll(cgPath)
-		// TODO: Try to correctly remove cgroup folders.
-		// if err != nil {
-		// 	fmt.Println(err)
-		// }
+	if Detect() == V2 {
+		Unified{parentFolder: parent, name: name}.Clean()
+		return
 	}
+
+	c := CGroups{
+		Folders:      map[string]CGroupFiles{},
+		parentFolder: parent,
+		name:         name,
+	}
+
+	entries, err := os.ReadDir(cgroupPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			c.Folders[entry.Name()] = CGroupFiles{}
+		}
+	}
+
+	c.Clean()
+}
+
+// removeTree removes the cgroup folder `cgPath` after the cgroup folders
+// below it. The interface files of a cgroup are removed by the kernel
+// along with its folder, which is only allowed once every process has
+// left the cgroup.
+func removeTree(cgPath string) {
+	entries, err := os.ReadDir(cgPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeTree(filepath.Join(cgPath, entry.Name()))
+		}
+	}
+
+	_ = os.Remove(cgPath)
 }
@@ -1,6 +1,8 @@
 package cgroups
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -58,3 +60,62 @@ func Test_cgroups_Load(t *testing.T) {
 		})
 	}
 }
+
+func Test_Remove(t *testing.T) {
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	testdata := map[string]struct {
+		version Version
+		folders []string
+	}{
+		"v1": {V1, []string{"memory", "cpu,cpuacct", "freezer"}},
+		"v2": {V2, []string{""}},
+	}
+
+	for name, test := range testdata {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			cgroupPath = t.TempDir()
+
+			if test.version == V2 {
+				err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.controllers"), nil, 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, folder := range test.folders {
+				for _, job := range []string{"job", "other"} {
+					err := os.MkdirAll(filepath.Join(cgroupPath, folder, "parent", job), 0700)
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			// Only the cgroup of the job is removed.
+			Remove("parent", "job")
+			for _, folder := range test.folders {
+				if _, err := os.Stat(filepath.Join(cgroupPath, folder, "parent", "job")); err == nil {
+					t.Fatalf("expected the cgroup of the job to be removed from %q", folder)
+				}
+
+				if _, err := os.Stat(filepath.Join(cgroupPath, folder, "parent", "other")); err != nil {
+					t.Fatalf("expected the other cgroup to be kept in %q: %v", folder, err)
+				}
+			}
+
+			// The parent is removed along with the cgroups below it.
+			Remove("parent", "")
+			for _, folder := range test.folders {
+				if _, err := os.Stat(filepath.Join(cgroupPath, folder, "parent")); err == nil {
+					t.Fatalf("expected the parent cgroup to be removed from %q", folder)
+				}
+
+				if _, err := os.Stat(filepath.Join(cgroupPath, folder)); err != nil {
+					t.Fatalf("expected %q to be kept: %v", folder, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package cgroups
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNoCGroup is returned by Freeze, Thaw and Kill when the cgroup does not
+// exist, e.g. because the helper was not permitted to create it.
+var ErrNoCGroup = errors.New("cgroup does not exist")
+
+// freezer is the interface of the freezer of a hierarchy: the folder of the
+// cgroups, the file which freezes the cgroup, the values which freeze and
+// thaw it and the file which reports that the cgroup is frozen. The state
+// is reported by the `key` of a flat keyed file, or by the whole file when
+// there is no key.
+type freezer struct {
+	folder string
+	file   string
+	frozen string
+	thawed string
+	events string
+	key    string
+}
+
+// freezers are the freezers of the legacy (v1) and unified (v2)
+// hierarchies.
+var freezers = map[Version]freezer{
+	V1: {"freezer", "freezer.state", "FROZEN", "THAWED", "freezer.state", ""},
+	V2: {"", "cgroup.freeze", "1", "0", "cgroup.events", "frozen"},
+}
+
+// frozenTimeout is how long Freeze waits for the processes of the cgroup
+// to be frozen.
+const frozenTimeout = time.Second
+
+// path returns the path of the cgroup `name` under the `parent` cgroup of
+// the freezer, or ErrNoCGroup when it does not exist.
+func (f freezer) path(parent, name string) (string, error) {
+	cgPath := filepath.Join(cgroupPath, f.folder, parent, name)
+	if _, err := os.Stat(cgPath); err != nil {
+		return "", ErrNoCGroup
+	}
+
+	return cgPath, nil
+}
+
+// set freezes or thaws the cgroup `cgPath`.
+func (f freezer) set(cgPath string, frozen bool) error {
+	value := f.thawed
+	if frozen {
+		value = f.frozen
+	}
+
+	return os.WriteFile(filepath.Join(cgPath, f.file), []byte(value), 0600)
+}
+
+// isFrozen reports whether every process of the cgroup `cgPath` is frozen.
+func (f freezer) isFrozen(cgPath string) bool {
+	if f.key == "" {
+		data, _ := os.ReadFile(filepath.Join(cgPath, f.events))
+		return strings.TrimSpace(string(data)) == f.frozen
+	}
+
+	return readKeys(filepath.Join(cgPath, f.events))[f.key] == 1
+}
+
+// Freeze freezes every process of the cgroup `name` under the `parent`
+// cgroup and waits for the processes to be frozen. Frozen processes do not
+// run until the cgroup is thawed.
+func Freeze(parent, name string) error {
+	f := freezers[Detect()]
+	cgPath, err := f.path(parent, name)
+	if err != nil {
+		return err
+	}
+
+	err = f.set(cgPath, true)
+	if err != nil {
+		return err
+	}
+
+	// Freezing is asynchronous, processes in an uninterruptible
+	// sleep are frozen once they return to user space.
+	for start := time.Now(); !f.isFrozen(cgPath) && time.Since(start) < frozenTimeout; {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	return nil
+}
+
+// Thaw resumes the processes of the cgroup `name` under the `parent`
+// cgroup frozen by Freeze.
+func Thaw(parent, name string) error {
+	f := freezers[Detect()]
+	cgPath, err := f.path(parent, name)
+	if err != nil {
+		return err
+	}
+
+	return f.set(cgPath, false)
+}
+
+// Kill kills every process of the cgroup `name` under the `parent` cgroup
+// except the process `keep`. The cgroup is frozen while the processes are
+// killed so they cannot fork new processes, and thawed afterwards so the
+// killed processes exit. A cgroup frozen by Freeze is thawed as well.
+//
+// NOTE: `cgroup.kill` is not used since it cannot spare a process, and the
+// helper which shares the cgroup with the job must outlive the job to
+// record its termination.
+func Kill(parent, name string, keep int) error {
+	f := freezers[Detect()]
+	cgPath, err := f.path(parent, name)
+	if err != nil {
+		return err
+	}
+
+	// The processes are killed even when the cgroup cannot be
+	// frozen, at the risk of missing processes forked meanwhile.
+	if f.set(cgPath, true) == nil {
+		defer func() { _ = f.set(cgPath, false) }()
+	}
+
+	for _, pid := range readPids(filepath.Join(cgPath, "cgroup.procs")) {
+		if pid == keep {
+			continue
+		}
+
+		err := syscall.Kill(pid, syscall.SIGKILL)
+		if err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readPids reads the PIDs listed one per line in the file at `path`.
+func readPids(path string) []int {
+	var pids []int
+	forEachLine(path, func(fields []string) {
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			pids = append(pids, pid)
+		}
+	})
+
+	return pids
+}
@@ -0,0 +1,134 @@
+package cgroups
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func Test_Kill(t *testing.T) {
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	tests := map[string]struct {
+		controllers bool
+		state       string
+		thawed      string
+	}{
+		"unified": {true, "testparent/job/cgroup.freeze", "0"},
+		"legacy":  {false, "freezer/testparent/job/freezer.state", "THAWED"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			cgroupPath = t.TempDir()
+
+			killed := exec.Command("sleep", "10")
+			kept := exec.Command("sleep", "10")
+			for _, cmd := range []*exec.Cmd{killed, kept} {
+				err := cmd.Start()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			defer func() { _ = kept.Process.Kill() }()
+
+			files := map[string]string{
+				filepath.Join(filepath.Dir(test.state), "cgroup.procs"): fmt.Sprintf(
+					"%d\n%d\n", killed.Process.Pid, kept.Process.Pid,
+				),
+			}
+
+			if test.controllers {
+				files["cgroup.controllers"] = "memory pids"
+			}
+
+			writeFiles(t, files)
+
+			err := Kill("testparent", "job", kept.Process.Pid)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = killed.Wait()
+			ws, _ := killed.ProcessState.Sys().(syscall.WaitStatus)
+			if err == nil || ws.Signal() != syscall.SIGKILL {
+				t.Fatalf("expected the process to be killed, got %v", err)
+			}
+
+			if kept.Process.Signal(syscall.Signal(0)) != nil {
+				t.Fatal("expected the kept process to be running")
+			}
+
+			state, err := os.ReadFile(filepath.Join(cgroupPath, test.state))
+			if err != nil || string(state) != test.thawed {
+				t.Fatalf("expected the cgroup to be thawed, got %q (%v)", state, err)
+			}
+
+			err = Kill("testparent", "missing", 0)
+			if !errors.Is(err, ErrNoCGroup) {
+				t.Fatalf("expected ErrNoCGroup, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_Freeze(t *testing.T) {
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	tests := map[string]struct {
+		files  map[string]string
+		state  string
+		frozen string
+		thawed string
+	}{
+		"unified": {
+			map[string]string{
+				"cgroup.controllers":           "memory pids",
+				"testparent/job/cgroup.events": "populated 1\nfrozen 1\n",
+			},
+			"testparent/job/cgroup.freeze", "1", "0",
+		},
+		"legacy": {
+			map[string]string{
+				"freezer/testparent/job/freezer.state": "THAWED",
+			},
+			"freezer/testparent/job/freezer.state", "FROZEN", "THAWED",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			cgroupPath = t.TempDir()
+			writeFiles(t, test.files)
+
+			for _, expected := range []string{test.frozen, test.thawed} {
+				var err error
+				if expected == test.frozen {
+					err = Freeze("testparent", "job")
+				} else {
+					err = Thaw("testparent", "job")
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				state, err := os.ReadFile(filepath.Join(cgroupPath, test.state))
+				if err != nil || string(state) != expected {
+					t.Fatalf("expected state %q, got %q (%v)", expected, state, err)
+				}
+			}
+
+			err := Freeze("testparent", "missing")
+			if !errors.Is(err, ErrNoCGroup) {
+				t.Fatalf("expected ErrNoCGroup, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package cgroups
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minCPUQuota is the smallest quota (in microseconds) accepted by the
+	// kernel's CFS bandwidth controller.
+	minCPUQuota = 1000
+
+	// minCPUPeriod and maxCPUPeriod are the bounds (in microseconds) the
+	// kernel enforces on the CFS period.
+	minCPUPeriod = 1000
+	maxCPUPeriod = 1000000
+)
+
+// ErrInvalidLimits is returned when a set of resource limits cannot be
+// applied to a cgroup.
+var ErrInvalidLimits = errors.New("invalid resource limits")
+
+// Limits describes the resource constraints for a single process tree. A
+// zero value for any field means the resource is not constrained.
+type Limits struct {
+	// Memory is the maximum amount of memory in bytes.
+	Memory int64 `json:"memory,omitempty"`
+
+	// CPUQuota is the amount of CPU time in microseconds the processes may
+	// use during each CPUPeriod.
+	CPUQuota int64 `json:"cpu_quota,omitempty"`
+
+	// CPUPeriod is the length of a CPU accounting period in microseconds.
+	// The kernel default (100ms) is used when this is not set.
+	CPUPeriod int64 `json:"cpu_period,omitempty"`
+
+	// IO is the set of per device bandwidth limits.
+	IO []IOLimit `json:"io,omitempty"`
+
+	// Pids is the maximum number of processes.
+	Pids int64 `json:"pids,omitempty"`
+}
+
+// IOLimit is a bandwidth limit for a single block device.
+type IOLimit struct {
+	// Device is the block device in the `major:minor` format.
+	Device string `json:"device"`
+
+	// ReadBPS is the maximum number of bytes read per second.
+	ReadBPS int64 `json:"read_bps,omitempty"`
+
+	// WriteBPS is the maximum number of bytes written per second.
+	WriteBPS int64 `json:"write_bps,omitempty"`
+}
+
+// Merge returns a copy of `l` where every limit set in `o` replaces the
+// limit in `l`.
+func (l Limits) Merge(o Limits) Limits {
+	if o.Memory != 0 {
+		l.Memory = o.Memory
+	}
+
+	if o.CPUQuota != 0 {
+		l.CPUQuota = o.CPUQuota
+	}
+
+	if o.CPUPeriod != 0 {
+		l.CPUPeriod = o.CPUPeriod
+	}
+
+	if len(o.IO) > 0 {
+		l.IO = o.IO
+	}
+
+	if o.Pids != 0 {
+		l.Pids = o.Pids
+	}
+
+	return l
+}
+
+// Validate verifies that the limits can be written to a cgroup.
+func (l Limits) Validate() error {
+	if l.Memory < 0 {
+		return fmt.Errorf("%w: memory must not be negative", ErrInvalidLimits)
+	}
+
+	if l.CPUQuota < 0 || (l.CPUQuota > 0 && l.CPUQuota < minCPUQuota) {
+		return fmt.Errorf(
+			"%w: cpu quota must be at least %dus",
+			ErrInvalidLimits,
+			minCPUQuota,
+		)
+	}
+
+	if l.CPUPeriod < 0 ||
+		(l.CPUPeriod > 0 && (l.CPUPeriod < minCPUPeriod || l.CPUPeriod > maxCPUPeriod)) {
+		return fmt.Errorf(
+			"%w: cpu period must be between %dus and %dus",
+			ErrInvalidLimits,
+			minCPUPeriod,
+			maxCPUPeriod,
+		)
+	}
+
+	if l.Pids < 0 {
+		return fmt.Errorf("%w: pids must not be negative", ErrInvalidLimits)
+	}
+
+	for _, io := range l.IO {
+		if !validDevice(io.Device) {
+			return fmt.Errorf(
+				"%w: device %q is not in the major:minor format",
+				ErrInvalidLimits,
+				io.Device,
+			)
+		}
+
+		if io.ReadBPS < 0 || io.WriteBPS < 0 {
+			return fmt.Errorf(
+				"%w: io bandwidth for device %s must not be negative",
+				ErrInvalidLimits,
+				io.Device,
+			)
+		}
+	}
+
+	return nil
+}
+
+// CGroups translates the limits into the cgroup v1 controller folders
+// and files.
+func (l Limits) CGroups() CGroups {
+	c := CGroups{Folders: map[string]CGroupFiles{}}
+
+	set := func(folder, file string, values ...string) {
+		f, ok := c.Folders[folder]
+		if !ok {
+			f = CGroupFiles{Files: map[string][]string{}}
+			c.Folders[folder] = f
+		}
+
+		f.Files[file] = append(f.Files[file], values...)
+	}
+
+	if l.Memory > 0 {
+		set("memory", "memory.limit_in_bytes", strconv.FormatInt(l.Memory, 10))
+	}
+
+	if l.CPUPeriod > 0 {
+		set("cpu", "cpu.cfs_period_us", strconv.FormatInt(l.CPUPeriod, 10))
+	}
+
+	if l.CPUQuota > 0 {
+		set("cpu", "cpu.cfs_quota_us", strconv.FormatInt(l.CPUQuota, 10))
+	}
+
+	for _, io := range l.IO {
+		// Devices which are not present on this host cannot be throttled
+		// and would fail the write, so they are skipped.
+		if !deviceExists(io.Device) {
+			continue
+		}
+
+		if io.ReadBPS > 0 {
+			set(
+				"blkio",
+				"blkio.throttle.read_bps_device",
+				fmt.Sprintf("%s %d", io.Device, io.ReadBPS),
+			)
+		}
+
+		if io.WriteBPS > 0 {
+			set(
+				"blkio",
+				"blkio.throttle.write_bps_device",
+				fmt.Sprintf("%s %d", io.Device, io.WriteBPS),
+			)
+		}
+	}
+
+	if l.Pids > 0 {
+		set("pids", "pids.max", strconv.FormatInt(l.Pids, 10))
+	}
+
+	return c
+}
+
+// validDevice checks that `device` is in the `major:minor` format.
+func validDevice(device string) bool {
+	parts := strings.Split(device, ":")
+	if len(parts) != 2 {
+		return false
+	}
+
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// devicePath is the sysfs directory listing the block devices of the host
+// by their `major:minor` numbers.
+var devicePath = "/sys/dev/block"
+
+// deviceExists checks sysfs for the block device.
+func deviceExists(device string) bool {
+	_, err := os.Stat(filepath.Join(devicePath, device))
+	return err == nil
+}
@@ -0,0 +1,120 @@
+package cgroups
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Limits_Validate(t *testing.T) {
+	testdata := map[string]struct {
+		limits Limits
+		valid  bool
+	}{
+		"empty": {
+			limits: Limits{},
+			valid:  true,
+		},
+		"full": {
+			limits: Limits{
+				Memory:    209715200,
+				CPUQuota:  50000,
+				CPUPeriod: 100000,
+				Pids:      64,
+				IO: []IOLimit{{
+					Device:   "8:0",
+					WriteBPS: 10485760,
+				}},
+			},
+			valid: true,
+		},
+		"negative-memory": {
+			limits: Limits{Memory: -1},
+		},
+		"small-cpu-quota": {
+			limits: Limits{CPUQuota: 10},
+		},
+		"large-cpu-period": {
+			limits: Limits{CPUPeriod: 2000000},
+		},
+		"negative-pids": {
+			limits: Limits{Pids: -1},
+		},
+		"invalid-device": {
+			limits: Limits{IO: []IOLimit{{Device: "sda", WriteBPS: 1}}},
+		},
+		"negative-bps": {
+			limits: Limits{IO: []IOLimit{{Device: "8:0", ReadBPS: -1}}},
+		},
+	}
+
+	for name, test := range testdata {
+		t.Run(name, func(t *testing.T) {
+			err := test.limits.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("expected limits to be valid, got %s", err)
+			}
+
+			if !test.valid && !errors.Is(err, ErrInvalidLimits) {
+				t.Fatalf("expected ErrInvalidLimits, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_Limits_Merge(t *testing.T) {
+	defaults := Limits{
+		Memory:   209715200,
+		CPUQuota: 100000,
+		IO:       []IOLimit{{Device: "8:0", WriteBPS: 10485760}},
+	}
+
+	merged := defaults.Merge(Limits{Memory: 1048576, Pids: 10})
+
+	if merged.Memory != 1048576 {
+		t.Fatalf("expected memory override, got %d", merged.Memory)
+	}
+
+	if merged.CPUQuota != defaults.CPUQuota {
+		t.Fatalf("expected default cpu quota, got %d", merged.CPUQuota)
+	}
+
+	if merged.Pids != 10 {
+		t.Fatalf("expected pids override, got %d", merged.Pids)
+	}
+
+	if len(merged.IO) != 1 || merged.IO[0] != defaults.IO[0] {
+		t.Fatalf("expected default io limits, got %v", merged.IO)
+	}
+}
+
+func Test_Limits_CGroups(t *testing.T) {
+	devicePath = t.TempDir()
+
+	c := Limits{
+		Memory:   209715200,
+		CPUQuota: 50000,
+		Pids:     64,
+		IO:       []IOLimit{{Device: "8:0", WriteBPS: 10485760}},
+	}.CGroups()
+
+	expected := map[string]map[string]string{
+		"memory": {"memory.limit_in_bytes": "209715200"},
+		"cpu":    {"cpu.cfs_quota_us": "50000"},
+		"pids":   {"pids.max": "64"},
+	}
+
+	for folder, files := range expected {
+		for file, value := range files {
+			values := c.Folders[folder].Files[file]
+			if len(values) != 1 || values[0] != value {
+				t.Fatalf("expected %s/%s to be %s, got %v", folder, file, value, values)
+			}
+		}
+	}
+
+	// The device does not exist in the temporary device path so the
+	// io limits must be skipped.
+	if _, ok := c.Folders["blkio"]; ok {
+		t.Fatal("expected missing device to be skipped")
+	}
+}
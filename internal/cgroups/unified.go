@@ -0,0 +1,195 @@
+package cgroups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Version identifies the cgroup hierarchy mounted on the host.
+type Version int
+
+const (
+	// V1 is the legacy hierarchy where each controller is mounted in its
+	// own folder under the cgroup path.
+	V1 Version = iota + 1
+
+	// V2 is the unified hierarchy where every controller is managed
+	// through a single cgroup folder.
+	V2
+)
+
+// defaultCPUPeriod is the kernel default CFS period in microseconds which
+// is used for `cpu.max` when only a quota is supplied.
+const defaultCPUPeriod = 100000
+
+// Detect inspects the cgroup mount to determine which hierarchy is in use.
+// Hybrid hosts, which mount the unified hierarchy next to the legacy
+// controllers, are treated as V1 since the controllers are bound there.
+func Detect() Version {
+	_, err := os.Stat(filepath.Join(cgroupPath, "cgroup.controllers"))
+	if err == nil {
+		return V2
+	}
+
+	return V1
+}
+
+// Unified represents the single per-job cgroup of the unified (v2)
+// hierarchy and the interface files to write in it.
+type Unified struct {
+	Files map[string][]string
+
+	procID       int
+	parentFolder string
+	name         string
+}
+
+// Unified translates the limits into the interface files of the unified
+// (v2) hierarchy.
+func (l Limits) Unified() Unified {
+	u := Unified{Files: map[string][]string{}}
+
+	if l.Memory > 0 {
+		u.Files["memory.max"] = []string{strconv.FormatInt(l.Memory, 10)}
+	}
+
+	if l.CPUQuota > 0 || l.CPUPeriod > 0 {
+		quota := "max"
+		if l.CPUQuota > 0 {
+			quota = strconv.FormatInt(l.CPUQuota, 10)
+		}
+
+		period := l.CPUPeriod
+		if period == 0 {
+			period = defaultCPUPeriod
+		}
+
+		u.Files["cpu.max"] = []string{fmt.Sprintf("%s %d", quota, period)}
+	}
+
+	for _, io := range l.IO {
+		// Devices which are not present on this host cannot be throttled
+		// and would fail the write, so they are skipped.
+		if !deviceExists(io.Device) {
+			continue
+		}
+
+		var bps []string
+		if io.ReadBPS > 0 {
+			bps = append(bps, fmt.Sprintf("rbps=%d", io.ReadBPS))
+		}
+
+		if io.WriteBPS > 0 {
+			bps = append(bps, fmt.Sprintf("wbps=%d", io.WriteBPS))
+		}
+
+		if len(bps) > 0 {
+			u.Files["io.max"] = append(
+				u.Files["io.max"],
+				fmt.Sprintf("%s %s", io.Device, strings.Join(bps, " ")),
+			)
+		}
+	}
+
+	if l.Pids > 0 {
+		u.Files["pids.max"] = []string{strconv.FormatInt(l.Pids, 10)}
+	}
+
+	return u
+}
+
+// controllers returns the controllers which must be enabled in the parent
+// cgroups for the interface files of `u` to exist, followed by the accounted
+// controllers which are available on the host.
+func (u Unified) controllers() []string {
+	available := map[string]bool{}
+	data, _ := os.ReadFile(filepath.Join(cgroupPath, "cgroup.controllers"))
+	for _, controller := range strings.Fields(string(data)) {
+		available[controller] = true
+	}
+
+	var enabled []string
+	for _, controller := range accountedV2 {
+		required := false
+		for file := range u.Files {
+			if strings.HasPrefix(file, controller+".") {
+				required = true
+				break
+			}
+		}
+
+		if required || available[controller] {
+			enabled = append(enabled, controller)
+		}
+	}
+
+	return enabled
+}
+
+// Write creates the job cgroup, enables the required controllers through
+// `cgroup.subtree_control` of every ancestor, writes the values to the
+// interface files and moves the process into the cgroup.
+func (u Unified) Write() error {
+	parent := filepath.Join(cgroupPath, u.parentFolder)
+	cgPath := filepath.Join(parent, u.name)
+
+	err := os.MkdirAll(cgPath, 0755)
+	if err != nil {
+		return err
+	}
+
+	// Controllers must be enabled top-down, from the root of the
+	// hierarchy to the parent folder of the job cgroup, before the
+	// interface files appear in the job cgroup.
+	controllers := u.controllers()
+	if len(controllers) > 0 {
+		enable := "+" + strings.Join(controllers, " +")
+		for _, folder := range []string{cgroupPath, parent} {
+			err = os.WriteFile(
+				filepath.Join(folder, "cgroup.subtree_control"),
+				[]byte(enable),
+				0600,
+			)
+			if err != nil {
+				return fmt.Errorf(
+					"failed to enable controllers [%s] in %s: %w",
+					strings.Join(controllers, " "),
+					folder,
+					err,
+				)
+			}
+		}
+	}
+
+	// Write values to the interface files for this group. Each value
+	// is written separately since the kernel only parses a single
+	// device entry per write for `io.max`.
+	for file, values := range u.Files {
+		for _, value := range values {
+			err = os.WriteFile(
+				filepath.Join(cgPath, file),
+				[]byte(value),
+				0600,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Add the current process to the cgroup.
+	return os.WriteFile(
+		filepath.Join(cgPath, "cgroup.procs"),
+		[]byte(strconv.Itoa(u.procID)),
+		0600,
+	)
+}
+
+// Clean attempts to remove the cgroup folder created by `u`. The kernel
+// only allows the removal once every process has left the cgroup.
+func (u Unified) Clean() {
+	removeTree(filepath.Join(cgroupPath, u.parentFolder, u.name))
+}
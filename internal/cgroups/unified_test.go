@@ -0,0 +1,112 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func Test_Limits_Unified(t *testing.T) {
+	devicePath = t.TempDir()
+	err := os.Mkdir(filepath.Join(devicePath, "8:0"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdata := map[string]struct {
+		limits   Limits
+		expected map[string][]string
+	}{
+		"memory": {
+			limits:   Limits{Memory: 209715200},
+			expected: map[string][]string{"memory.max": {"209715200"}},
+		},
+		"cpu-quota": {
+			limits:   Limits{CPUQuota: 50000},
+			expected: map[string][]string{"cpu.max": {"50000 100000"}},
+		},
+		"cpu-period": {
+			limits:   Limits{CPUPeriod: 200000},
+			expected: map[string][]string{"cpu.max": {"max 200000"}},
+		},
+		"io": {
+			limits: Limits{IO: []IOLimit{
+				{Device: "8:0", ReadBPS: 1024, WriteBPS: 2048},
+				{Device: "259:0", WriteBPS: 2048},
+			}},
+			expected: map[string][]string{"io.max": {"8:0 rbps=1024 wbps=2048"}},
+		},
+		"pids": {
+			limits:   Limits{Pids: 64},
+			expected: map[string][]string{"pids.max": {"64"}},
+		},
+	}
+
+	for name, test := range testdata {
+		t.Run(name, func(t *testing.T) {
+			u := test.limits.Unified()
+
+			if len(u.Files) != len(test.expected) {
+				t.Fatalf("expected %d files, got %v", len(test.expected), u.Files)
+			}
+
+			for file, values := range test.expected {
+				if len(u.Files[file]) != len(values) {
+					t.Fatalf("expected %s to be %v, got %v", file, values, u.Files[file])
+				}
+
+				for i, value := range values {
+					if u.Files[file][i] != value {
+						t.Fatalf("expected %s, got %s", value, u.Files[file][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_LimitResources_Unified(t *testing.T) {
+	cgroupPath = t.TempDir()
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	err := os.WriteFile(
+		filepath.Join(cgroupPath, "cgroup.controllers"),
+		[]byte("cpu io memory pids"),
+		0600,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if Detect() != V2 {
+		t.Fatal("expected unified hierarchy to be detected")
+	}
+
+	err = LimitResources("testparent", "job", Limits{
+		Memory:   209715200,
+		CPUQuota: 50000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"cgroup.subtree_control":            "+memory +cpu +io +pids",
+		"testparent/cgroup.subtree_control": "+memory +cpu +io +pids",
+		"testparent/job/memory.max":         "209715200",
+		"testparent/job/cpu.max":            "50000 100000",
+		"testparent/job/cgroup.procs":       strconv.Itoa(os.Getpid()),
+	}
+
+	for file, value := range expected {
+		data, err := os.ReadFile(filepath.Join(cgroupPath, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != value {
+			t.Fatalf("expected %s to be %q, got %q", file, value, data)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package cgroups
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoUsage is returned by ReadUsage when the cgroup does not exist, e.g.
+// because the helper was not permitted to create it.
+var ErrNoUsage = errors.New("resource usage is not available")
+
+// Usage is the resource usage of the processes of a cgroup. Usage which is
+// not accounted by the hierarchy of the host is left as a zero value.
+type Usage struct {
+	// Memory is the amount of memory in bytes currently used and
+	// MemoryPeak is the largest amount of memory in bytes used.
+	Memory     int64
+	MemoryPeak int64
+
+	// CPUTime is the CPU time consumed by the processes.
+	CPUTime time.Duration
+
+	// ThrottledPeriods is the number of CPU accounting periods in which
+	// the processes were throttled by the CPU quota.
+	ThrottledPeriods int64
+
+	// IORead and IOWrite are the number of bytes read from and written
+	// to block devices.
+	IORead  int64
+	IOWrite int64
+
+	// Pids is the number of processes.
+	Pids int64
+}
+
+// accountedV1 and accountedV2 are the controllers the processes of a job
+// are added to on either hierarchy, so their usage is accounted even when
+// the resource is not limited. The freezer of the legacy hierarchy is
+// included so the processes can be frozen, see Freeze and Kill.
+var (
+	accountedV1 = []string{"memory", "cpu", "cpuacct", "blkio", "pids", "freezer"}
+	accountedV2 = []string{"memory", "cpu", "io", "pids"}
+)
+
+// ReadUsage reads the resource usage of the cgroup `name` under the
+// `parent` cgroup from the hierarchy mounted on the host.
+func ReadUsage(parent, name string) (Usage, error) {
+	if Detect() == V2 {
+		return readUnifiedUsage(filepath.Join(cgroupPath, parent, name))
+	}
+
+	return readUsage(parent, name)
+}
+
+// OOMKilled reports whether the OOM killer killed a process of the cgroup
+// `name` under the `parent` cgroup.
+func OOMKilled(parent, name string) bool {
+	if Detect() == V2 {
+		return readKeys(filepath.Join(cgroupPath, parent, name, "memory.events"))["oom_kill"] > 0
+	}
+
+	return readKeys(filepath.Join(cgroupPath, "memory", parent, name, "memory.oom_control"))["oom_kill"] > 0
+}
+
+// readUnifiedUsage reads the usage from the interface files of the cgroup
+// `cgPath` of the unified (v2) hierarchy.
+func readUnifiedUsage(cgPath string) (Usage, error) {
+	if _, err := os.Stat(cgPath); err != nil {
+		return Usage{}, ErrNoUsage
+	}
+
+	cpu := readKeys(filepath.Join(cgPath, "cpu.stat"))
+	io := readIOStat(filepath.Join(cgPath, "io.stat"))
+
+	return Usage{
+		Memory:           readInt(filepath.Join(cgPath, "memory.current")),
+		MemoryPeak:       readInt(filepath.Join(cgPath, "memory.peak")),
+		CPUTime:          time.Duration(cpu["usage_usec"]) * time.Microsecond,
+		ThrottledPeriods: cpu["nr_throttled"],
+		IORead:           io["rbytes"],
+		IOWrite:          io["wbytes"],
+		Pids:             readInt(filepath.Join(cgPath, "pids.current")),
+	}, nil
+}
+
+// readUsage reads the usage from the controller folders of the cgroup
+// `name` under the `parent` cgroup of the legacy (v1) hierarchy.
+func readUsage(parent, name string) (Usage, error) {
+	path := func(folder, file string) string {
+		return filepath.Join(cgroupPath, folder, parent, name, file)
+	}
+
+	found := false
+	for _, folder := range accountedV1 {
+		if _, err := os.Stat(path(folder, "")); err == nil {
+			found = true
+		}
+	}
+
+	if !found {
+		return Usage{}, ErrNoUsage
+	}
+
+	io := readServiceBytes(path("blkio", "blkio.throttle.io_service_bytes"))
+
+	return Usage{
+		Memory:           readInt(path("memory", "memory.usage_in_bytes")),
+		MemoryPeak:       readInt(path("memory", "memory.max_usage_in_bytes")),
+		CPUTime:          time.Duration(readInt(path("cpuacct", "cpuacct.usage"))),
+		ThrottledPeriods: readKeys(path("cpu", "cpu.stat"))["nr_throttled"],
+		IORead:           io["Read"],
+		IOWrite:          io["Write"],
+		Pids:             readInt(path("pids", "pids.current")),
+	}, nil
+}
+
+// readInt reads a file containing a single integer. Files which do not
+// exist or cannot be parsed read as zero.
+func readInt(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	v, _ := strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64)
+	return v
+}
+
+// readKeys reads a flat keyed file with a `key value` pair per line, such
+// as `cpu.stat`.
+func readKeys(path string) map[string]int64 {
+	keys := map[string]int64{}
+	forEachLine(path, func(fields []string) {
+		if len(fields) == 2 { //nolint:gomnd // key and value
+			keys[fields[0]], _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	})
+
+	return keys
+}
+
+// readIOStat sums the `key=value` pairs of every device of the `io.stat`
+// file of the unified hierarchy.
+func readIOStat(path string) map[string]int64 {
+	keys := map[string]int64{}
+	forEachLine(path, func(fields []string) {
+		// The first field is the device.
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2) //nolint:gomnd // key and value
+			if len(kv) != 2 {                   //nolint:gomnd // key and value
+				continue
+			}
+
+			v, _ := strconv.ParseInt(kv[1], 10, 64)
+			keys[kv[0]] += v
+		}
+	})
+
+	return keys
+}
+
+// readServiceBytes sums the operations of every device of the
+// `blkio.throttle.io_service_bytes` file of the legacy hierarchy, which
+// has a `device operation bytes` entry per line.
+func readServiceBytes(path string) map[string]int64 {
+	keys := map[string]int64{}
+	forEachLine(path, func(fields []string) {
+		if len(fields) == 3 { //nolint:gomnd // device, operation and bytes
+			v, _ := strconv.ParseInt(fields[2], 10, 64)
+			keys[fields[1]] += v
+		}
+	})
+
+	return keys
+}
+
+// forEachLine calls `fn` with the fields of every non-empty line of the
+// file at `path`. Files which do not exist have no lines.
+func forEachLine(path string, fn func(fields []string)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			fn(fields)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cgroups
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFiles writes the files relative to the cgroup path.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for file, data := range files {
+		path := filepath.Join(cgroupPath, file)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(data), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_ReadUsage(t *testing.T) {
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	expected := Usage{
+		Memory:           4096,
+		MemoryPeak:       8192,
+		CPUTime:          time.Millisecond * 1500,
+		ThrottledPeriods: 3,
+		IORead:           300,
+		IOWrite:          500,
+		Pids:             2,
+	}
+
+	testdata := map[string]map[string]string{
+		"unified": {
+			"cgroup.controllers":            "cpu io memory pids",
+			"testparent/job/memory.current": "4096\n",
+			"testparent/job/memory.peak":    "8192\n",
+			"testparent/job/cpu.stat": "usage_usec 1500000\nuser_usec 1000000\n" +
+				"nr_periods 10\nnr_throttled 3\n",
+			"testparent/job/io.stat": "8:0 rbytes=100 wbytes=200 rios=1 wios=2\n" +
+				"8:16 rbytes=200 wbytes=300 rios=1 wios=2\n",
+			"testparent/job/pids.current": "2\n",
+		},
+		"legacy": {
+			"memory/testparent/job/memory.usage_in_bytes":     "4096\n",
+			"memory/testparent/job/memory.max_usage_in_bytes": "8192\n",
+			"cpuacct/testparent/job/cpuacct.usage":            "1500000000\n",
+			"cpu/testparent/job/cpu.stat":                     "nr_periods 10\nnr_throttled 3\n",
+			"blkio/testparent/job/blkio.throttle.io_service_bytes": "8:0 Read 100\n8:0 Write 200\n" +
+				"8:16 Read 200\n8:16 Write 300\nTotal 800\n",
+			"pids/testparent/job/pids.current": "2\n",
+		},
+	}
+
+	for name, files := range testdata {
+		files := files
+		t.Run(name, func(t *testing.T) {
+			cgroupPath = t.TempDir()
+			writeFiles(t, files)
+
+			usage, err := ReadUsage("testparent", "job")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if usage != expected {
+				t.Fatalf("expected %+v, got %+v", expected, usage)
+			}
+
+			_, err = ReadUsage("testparent", "missing")
+			if !errors.Is(err, ErrNoUsage) {
+				t.Fatalf("expected ErrNoUsage, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_OOMKilled(t *testing.T) {
+	t.Cleanup(func() { cgroupPath = "/sys/fs/cgroup" })
+
+	testdata := map[string]map[string]string{
+		"unified": {
+			"cgroup.controllers":           "memory",
+			"testparent/job/memory.events": "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1\n",
+			"testparent/ok/memory.events":  "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n",
+		},
+		"legacy": {
+			"memory/testparent/job/memory.oom_control": "oom_kill_disable 0\nunder_oom 0\noom_kill 1\n",
+			"memory/testparent/ok/memory.oom_control":  "oom_kill_disable 0\nunder_oom 0\noom_kill 0\n",
+		},
+	}
+
+	for name, files := range testdata {
+		files := files
+		t.Run(name, func(t *testing.T) {
+			cgroupPath = t.TempDir()
+			writeFiles(t, files)
+
+			if !OOMKilled("testparent", "job") {
+				t.Fatal("expected the job to be OOM killed")
+			}
+
+			if OOMKilled("testparent", "ok") || OOMKilled("testparent", "missing") {
+				t.Fatal("expected the job not to be OOM killed")
+			}
+		})
+	}
+}
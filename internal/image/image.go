@@ -0,0 +1,246 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned by Extract when an entry of the image would
+// be written outside of the destination directory.
+var ErrUnsafePath = errors.New("unsafe path in image")
+
+// gzipMagic is the header of gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Owner maps the owner of an entry of the image to the owner of the
+// extracted file.
+type Owner func(uid, gid int) (int, int)
+
+// Extract unpacks the tarball `src`, which may be gzip compressed, into
+// the directory `dst`. The permissions of the entries are preserved and
+// their ownership is mapped using `owner`, or preserved when `owner` is
+// nil. Device nodes are skipped since the sandbox provides its own /dev
+// to the isolated command.
+func Extract(src, dst string, owner Owner) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	magic, err := r.(*bufio.Reader).Peek(len(gzipMagic))
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+
+		r = gz
+	}
+
+	err = os.MkdirAll(dst, 0755) //nolint:gomnd // default directory mode
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if owner != nil {
+			hdr.Uid, hdr.Gid = owner(hdr.Uid, hdr.Gid)
+		}
+
+		err = extractEntry(tr, hdr, dst)
+		if err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// extractEntry writes a single entry of the tarball into `dst`.
+func extractEntry(r io.Reader, hdr *tar.Header, dst string) error {
+	target, err := securePath(dst, hdr.Name)
+	if err != nil {
+		return err
+	}
+
+	// The root of the image is the destination directory itself.
+	if target == dst {
+		return nil
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		err = mkdir(target)
+	case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // older tarballs
+		err = writeFile(r, target)
+	case tar.TypeSymlink:
+		err = replace(target, func() error {
+			return os.Symlink(hdr.Linkname, target)
+		})
+	case tar.TypeLink:
+		var source string
+		source, err = securePath(dst, hdr.Linkname)
+		if err != nil {
+			return err
+		}
+
+		// A hard link to a symbolic link would apply the permissions
+		// of the entry to the target of the symbolic link, which may
+		// be outside of the root.
+		err = notSymlink(source)
+		if err != nil {
+			return err
+		}
+
+		err = replace(target, func() error {
+			return os.Link(source, target)
+		})
+	default:
+		// Device nodes, fifos and other special files are
+		// not extracted.
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Lchown(target, hdr.Uid, hdr.Gid)
+	if err != nil {
+		return err
+	}
+
+	// The permissions of symbolic links are not used, and are never
+	// applied through a symbolic link.
+	info, err := os.Lstat(target)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	return os.Chmod(target, hdr.FileInfo().Mode())
+}
+
+// notSymlink returns ErrUnsafePath when the file at `path` is a symbolic
+// link.
+func notSymlink(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return ErrUnsafePath
+	}
+
+	return nil
+}
+
+// mkdir creates the directory `target` unless it already exists. Any
+// other file at `target` is replaced so the permissions of the entry are
+// never applied through a symbolic link.
+func mkdir(target string) error {
+	info, err := os.Lstat(target)
+	if err == nil && info.IsDir() {
+		return nil
+	}
+
+	return replace(target, func() error {
+		return os.Mkdir(target, 0755) //nolint:gomnd // default directory mode
+	})
+}
+
+// writeFile writes the contents of a regular file entry to `target`.
+func writeFile(r io.Reader, target string) error {
+	return replace(target, func() error {
+		f, err := os.OpenFile(
+			target,
+			os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+			0600, //nolint:gomnd // the final mode is applied after writing
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(f, r)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+
+		return f.Close()
+	})
+}
+
+// replace removes an existing file at `target`, so a later entry of the
+// tarball replaces an earlier one, before creating it using `create`.
+func replace(target string, create func() error) error {
+	err := os.Remove(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return create()
+}
+
+// securePath resolves the entry `name` within `root`. An error is returned
+// when any parent directory of the entry is a symbolic link since the link
+// could point outside of `root`.
+func securePath(root, name string) (string, error) {
+	// Cleaning the name as an absolute path removes any `..`
+	// elements which would leave the root.
+	rel := strings.TrimPrefix(filepath.Clean("/"+name), "/")
+	if rel == "" {
+		return root, nil
+	}
+
+	parts := strings.Split(rel, "/")
+
+	dir := root
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+
+		info, err := os.Lstat(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			// Missing parent directories are created.
+			err = os.Mkdir(dir, 0755) //nolint:gomnd // default directory mode
+			if err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if !info.IsDir() {
+			return "", ErrUnsafePath
+		}
+	}
+
+	return filepath.Join(root, rel), nil
+}
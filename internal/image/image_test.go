@@ -0,0 +1,137 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// entry is a tarball entry used to build test images.
+type entry struct {
+	hdr  tar.Header
+	data string
+}
+
+func writeImage(t *testing.T, entries []entry) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		e.hdr.Size = int64(len(e.data))
+		err := tw.WriteHeader(&e.hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = tw.Write([]byte(e.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := tw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "image.tar")
+	err = os.WriteFile(path, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func Test_Extract(t *testing.T) {
+	src := writeImage(t, []entry{
+		{hdr: tar.Header{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0755}},
+		{hdr: tar.Header{Name: "bin/tool", Typeflag: tar.TypeReg, Mode: 0755}, data: "tool"},
+		{hdr: tar.Header{Name: "bin/alias", Typeflag: tar.TypeSymlink, Linkname: "tool"}},
+		{hdr: tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644}, data: "inside"},
+		{hdr: tar.Header{Name: "dev/null", Typeflag: tar.TypeChar, Mode: 0666}},
+	})
+
+	dst := filepath.Join(t.TempDir(), "root")
+	err := Extract(src, dst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "bin", "alias"))
+	if err != nil || string(data) != "tool" {
+		t.Fatalf("expected the symlink to resolve to the tool, got %q: %v", data, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "bin", "tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0755 {
+		t.Fatalf("expected mode 0755, got %s", info.Mode())
+	}
+
+	// Entries leaving the root are extracted within the root.
+	_, err = os.Stat(filepath.Join(dst, "escape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = os.Lstat(filepath.Join(dst, "dev", "null"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected device nodes to be skipped, got %v", err)
+	}
+}
+
+func Test_Extract_symlink_escape(t *testing.T) {
+	outside := t.TempDir()
+
+	src := writeImage(t, []entry{
+		{hdr: tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outside}},
+		{hdr: tar.Header{Name: "link/file", Typeflag: tar.TypeReg, Mode: 0644}, data: "escaped"},
+	})
+
+	err := Extract(src, filepath.Join(t.TempDir(), "root"), nil)
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+
+	_, err = os.Stat(filepath.Join(outside, "file"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected no file to be written through the symlink")
+	}
+}
+
+func Test_Extract_hardlink_symlink(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "shadow")
+	err := os.WriteFile(outside, []byte("secret"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hard link to the symbolic link would chmod the file outside
+	// of the root through the symbolic link.
+	src := writeImage(t, []entry{
+		{hdr: tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside}},
+		{hdr: tar.Header{Name: "b", Typeflag: tar.TypeLink, Linkname: "a", Mode: 0777}},
+	})
+
+	err = Extract(src, filepath.Join(t.TempDir(), "root"), nil)
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+
+	info, err := os.Stat(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the mode of the file outside of the root to be kept, got %s", info.Mode())
+	}
+}
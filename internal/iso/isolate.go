@@ -1,22 +1,93 @@
 package iso
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"syscall"
 )
 
+// IDMap maps a range of user or group IDs in the user namespace of the
+// isolated command to a range of IDs on the host.
+type IDMap struct {
+	ContainerID int `json:"container_id"`
+	HostID      int `json:"host_id"`
+	Size        int `json:"size"`
+}
+
+// selfExe is the path of the executable of the current process.
+const selfExe = "/proc/self/exe"
+
+// maxIDMaps is the maximum number of ranges the kernel accepts in the
+// uid_map and gid_map files.
+const maxIDMaps = 340
+
+// ErrInvalidIDMap is returned by ValidateIDMaps for ID maps which cannot
+// be applied to a user namespace.
+var ErrInvalidIDMap = errors.New("invalid id map")
+
+// ValidateIDMaps checks that the ranges of `maps` are valid, do not
+// overlap and map the root (ID 0) of the user namespace.
+func ValidateIDMaps(maps []IDMap) error {
+	if len(maps) == 0 || len(maps) > maxIDMaps {
+		return fmt.Errorf("%w: expected 1 to %d ranges", ErrInvalidIDMap, maxIDMaps)
+	}
+
+	root := false
+	for i, m := range maps {
+		if m.ContainerID < 0 || m.HostID < 0 || m.Size <= 0 {
+			return fmt.Errorf("%w: invalid range %+v", ErrInvalidIDMap, m)
+		}
+
+		for _, o := range maps[:i] {
+			if m.ContainerID < o.ContainerID+o.Size && o.ContainerID < m.ContainerID+m.Size {
+				return fmt.Errorf("%w: overlapping ranges %+v and %+v", ErrInvalidIDMap, o, m)
+			}
+		}
+
+		if m.ContainerID == 0 {
+			root = true
+		}
+	}
+
+	if !root {
+		return fmt.Errorf("%w: root is not mapped", ErrInvalidIDMap)
+	}
+
+	return nil
+}
+
+// HostID returns the host ID which `id` in the user namespace is mapped
+// to by `maps`.
+func HostID(maps []IDMap, id int) (int, bool) {
+	for _, m := range maps {
+		if id >= m.ContainerID && id < m.ContainerID+m.Size {
+			return m.HostID + id - m.ContainerID, true
+		}
+	}
+
+	return 0, false
+}
+
 // Isolate creates an *exec.Cmd with isolation syscall flags for execution and
 // maps the command's stdin, stdout, and stderr to that of the parent process.
 //
+// When ID maps are provided the command is started in a new user namespace
+// using the maps, so the root of the command is not the root of the host.
+//
 // TODO: This function has a hardcoded call to `sub` as an argument to the
 // currently running command. This is not correct for a production system and
 // should be made configurable in the future.
 // TODO: It also assumes the position of arguments is correct. This is likely
 // not the case for a production system.
-func Isolate() *exec.Cmd {
+func Isolate(uidMap, gidMap []IDMap) *exec.Cmd {
+	// NOTE: The current executable is executed through /proc/self/exe
+	// since the isolated helper runs as an unprivileged user of the
+	// host which may not be able to traverse the parent directories
+	// of the executable.
 	// nolint:gosec
-	cmd := exec.Command(os.Args[0], append([]string{"sub"}, os.Args[2:]...)...)
+	cmd := exec.Command(selfExe, append([]string{"sub"}, os.Args[2:]...)...)
 
 	// Pipe in the parent's stdin and pipe out the child's stdout and stderr
 	cmd.Stdin = os.Stdin
@@ -32,5 +103,56 @@ func Isolate() *exec.Cmd {
 		Unshareflags: syscall.CLONE_NEWNS,
 	}
 
+	if len(uidMap) > 0 || len(gidMap) > 0 {
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER
+		cmd.SysProcAttr.UidMappings = sysIDMaps(uidMap)
+		cmd.SysProcAttr.GidMappings = sysIDMaps(gidMap)
+
+		// The kernel only allows unprivileged processes to write
+		// the gid_map once setgroups is denied.
+		privileged := os.Geteuid() == 0
+		cmd.SysProcAttr.GidMappingsEnableSetgroups = privileged
+
+		// Switch to the root of the user namespace, otherwise the
+		// command keeps the unmapped IDs of this process and loses
+		// its capabilities within the namespace on exec.
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:         0,
+			Gid:         0,
+			NoSetGroups: !privileged,
+		}
+	}
+
 	return cmd
 }
+
+// sysIDMaps converts the ID maps to the format used by exec.Cmd.
+func sysIDMaps(maps []IDMap) []syscall.SysProcIDMap {
+	s := make([]syscall.SysProcIDMap, 0, len(maps))
+	for _, m := range maps {
+		s = append(s, syscall.SysProcIDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		})
+	}
+
+	return s
+}
+
+// Terminal attaches the command created by Isolate to the slave end of a
+// pseudo-terminal. The command becomes the leader of a new session with
+// the terminal as its controlling terminal so job control and terminal
+// signals work for interactive tools such as shells and REPLs.
+func Terminal(cmd *exec.Cmd, tty *os.File) {
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+
+	// Ctty is the file descriptor of the terminal in the child which
+	// is stdin.
+	cmd.SysProcAttr.Ctty = 0
+}
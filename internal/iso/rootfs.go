@@ -0,0 +1,251 @@
+package iso
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// devices are the device nodes of the host which are bind mounted into
+// the /dev of the root filesystem.
+var devices = []string{"null", "zero", "full", "random", "urandom", "tty"}
+
+// devLinks are the symbolic links created in the /dev of the root
+// filesystem.
+var devLinks = map[string]string{
+	"fd":     "/proc/self/fd",
+	"stdin":  "/proc/self/fd/0",
+	"stdout": "/proc/self/fd/1",
+	"stderr": "/proc/self/fd/2",
+	"ptmx":   "pts/ptmx",
+}
+
+// staging is the directory a private tmpfs is mounted on to stage the
+// root filesystem before pivoting into it.
+const staging = "/tmp"
+
+// mountPoints are the directories of the root filesystem which are mounted
+// on by PivotRoot.
+var mountPoints = []string{"proc", "dev", "tmp"}
+
+// MountPoints creates the directories of the root filesystem `root` which
+// are mounted on by PivotRoot. It is called before isolating since the
+// isolated helper may not be permitted to create them.
+func MountPoints(root string) error {
+	for _, dir := range mountPoints {
+		err := os.MkdirAll(filepath.Join(root, dir), 0755) //nolint:gomnd // default directory mode
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PivotRoot changes the root filesystem of the calling process, which must
+// be running in its own mount namespace, to the directory `root`. The root
+// filesystem is mounted read-only with a fresh /proc, /dev and /tmp, whose
+// mount points must have been created by MountPoints. The filesystem of the
+// host is detached so it is no longer accessible.
+func PivotRoot(root string) error {
+	// Keep the mounts below from propagating to the host.
+	err := mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, "")
+	if err != nil {
+		return err
+	}
+
+	// The new root must be a mount point so it is bind mounted
+	// onto a directory of a private tmpfs.
+	err = mount("tmpfs", staging, "tmpfs", 0, "mode=700")
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(staging, "root")
+	err = os.Mkdir(target, 0700) //nolint:gomnd // private directory mode
+	if err != nil {
+		return err
+	}
+
+	err = mount(root, target, "", syscall.MS_BIND|syscall.MS_REC, "")
+	if err != nil {
+		return err
+	}
+
+	root = target
+
+	// Mount the filesystems before the root becomes read-only.
+	err = mount(
+		"proc",
+		filepath.Join(root, "proc"),
+		"proc",
+		syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC,
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	err = mountDev(filepath.Join(root, "dev"))
+	if err != nil {
+		return err
+	}
+
+	err = mount(
+		"tmpfs",
+		filepath.Join(root, "tmp"),
+		"tmpfs",
+		syscall.MS_NOSUID|syscall.MS_NODEV,
+		"mode=1777",
+	)
+	if err != nil {
+		return err
+	}
+
+	// The flags of the existing mount are kept since the kernel
+	// does not allow clearing flags such as nosuid or nodev of a
+	// mount inherited by a user namespace.
+	var st syscall.Statfs_t
+	err = syscall.Statfs(root, &st)
+	if err != nil {
+		return err
+	}
+
+	err = mount(
+		root,
+		root,
+		"",
+		syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|mountFlags(st.Flags),
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	// Pivoting the root onto itself stacks the old root on top
+	// of the new root so no directory is needed for the old root.
+	err = os.Chdir(root)
+	if err != nil {
+		return err
+	}
+
+	err = syscall.PivotRoot(".", ".")
+	if err != nil {
+		return fmt.Errorf("pivot_root %s: %w", root, err)
+	}
+
+	err = syscall.Unmount(".", syscall.MNT_DETACH)
+	if err != nil {
+		return fmt.Errorf("unmount host root: %w", err)
+	}
+
+	return os.Chdir("/")
+}
+
+// mountDev mounts a minimal /dev at `dev` containing the common device
+// nodes of the host and a private instance of devpts.
+func mountDev(dev string) error {
+	err := mount(
+		"tmpfs",
+		dev,
+		"tmpfs",
+		syscall.MS_NOSUID|syscall.MS_NOEXEC,
+		"mode=755",
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range devices {
+		target := filepath.Join(dev, name)
+
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_ = f.Close()
+
+		err = mount(filepath.Join("/dev", name), target, "", syscall.MS_BIND, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, target := range devLinks {
+		err = os.Symlink(target, filepath.Join(dev, name))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range []string{"pts", "shm"} {
+		err = os.Mkdir(filepath.Join(dev, dir), 0755) //nolint:gomnd // default directory mode
+		if err != nil {
+			return err
+		}
+	}
+
+	err = mount(
+		"devpts",
+		filepath.Join(dev, "pts"),
+		"devpts",
+		syscall.MS_NOSUID|syscall.MS_NOEXEC,
+		"newinstance,ptmxmode=0666,mode=0620",
+	)
+	if err != nil {
+		return err
+	}
+
+	return mount(
+		"tmpfs",
+		filepath.Join(dev, "shm"),
+		"tmpfs",
+		syscall.MS_NOSUID|syscall.MS_NODEV,
+		"mode=1777",
+	)
+}
+
+// mount wraps syscall.Mount to add the target to the error.
+func mount(source, target, fstype string, flags uintptr, data string) error {
+	err := syscall.Mount(source, target, fstype, flags, data)
+	if err != nil {
+		return fmt.Errorf("mount %s: %w", target, err)
+	}
+
+	return nil
+}
+
+// statfsFlags maps the statfs flags of a mount to the mount flags which
+// are locked for mounts inherited by a user namespace.
+var statfsFlags = map[int64]uintptr{
+	stNoSuid:     syscall.MS_NOSUID,
+	stNoDev:      syscall.MS_NODEV,
+	stNoExec:     syscall.MS_NOEXEC,
+	stNoAtime:    syscall.MS_NOATIME,
+	stNoDirAtime: syscall.MS_NODIRATIME,
+	stRelAtime:   syscall.MS_RELATIME,
+}
+
+// The ST_* flags of statfs(2) which are not defined by the syscall
+// package.
+const (
+	stNoSuid     = 0x2
+	stNoDev      = 0x4
+	stNoExec     = 0x8
+	stNoAtime    = 0x400
+	stNoDirAtime = 0x800
+	stRelAtime   = 0x1000
+)
+
+// mountFlags returns the mount flags for the statfs flags of a mount.
+func mountFlags(flags int64) uintptr {
+	var m uintptr
+	for st, ms := range statfsFlags {
+		if flags&st != 0 {
+			m |= ms
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ipForward is the sysctl which enables routing IPv4 traffic between the
+// interfaces of the host.
+const ipForward = "/proc/sys/net/ipv4/ip_forward"
+
+// SetupBridge creates the bridge `name` on the host, assigns it the address
+// `gateway` in CIDR notation and NATs the traffic of the subnet of the
+// gateway which leaves the host through another interface. Existing
+// configuration is kept so the bridge can be shared by several sandboxes.
+//
+// Jobs are isolated from the host and from each other: the traffic of the
+// bridge to the host, including the gateway address, is dropped and so is
+// the traffic routed from the bridge back to it. The ports of the jobs are
+// isolated by Attach so the jobs cannot reach each other on the bridge
+// either.
+//
+// NOTE: The bridge, the forwarding and the rules are left in place when
+// the sandbox is cleaned up since other sandboxes may still be using them.
+//
+// NOTE: Only the host and the other jobs are isolated. Traffic to other
+// networks the host routes to, e.g. the local network of the host, is
+// forwarded like traffic to the internet.
+func SetupBridge(name, gateway string) error {
+	_, subnet, err := net.ParseCIDR(gateway)
+	if err != nil {
+		return err
+	}
+
+	if _, err = net.InterfaceByName(name); err != nil {
+		err = run("ip", "link", "add", name, "type", "bridge")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = run("ip", "addr", "replace", gateway, "dev", name)
+	if err != nil {
+		return err
+	}
+
+	// The drop rules are in place before the bridge is up so no
+	// traffic of a job reaches the host without them.
+	err = ensureRule("filter", "-I", "INPUT", "-i", name, "-j", "DROP")
+	if err != nil {
+		return err
+	}
+
+	err = ensureRule("filter", "-I", "FORWARD", "-i", name, "-o", name, "-j", "DROP")
+	if err != nil {
+		return err
+	}
+
+	err = run("ip", "link", "set", name, "up")
+	if err != nil {
+		return err
+	}
+
+	// nolint:gosec
+	err = os.WriteFile(ipForward, []byte("1"), 0644)
+	if err != nil {
+		return err
+	}
+
+	return ensureRule(
+		"nat", "-A", "POSTROUTING",
+		"-s", subnet.String(),
+		"!", "-o", name,
+		"-j", "MASQUERADE",
+	)
+}
+
+// ensureRule adds the iptables rule `rule` to the table `table` using the
+// command `add`, i.e. "-A" to append or "-I" to insert it first in the
+// chain. The rule is only added when the check fails so it is not
+// duplicated by every sandbox using the bridge.
+func ensureRule(table, add string, rule ...string) error {
+	err := run("iptables", append([]string{"-t", table, "-C"}, rule...)...)
+	if err == nil {
+		return nil
+	}
+
+	return run("iptables", append([]string{"-t", table, add}, rule...)...)
+}
+
+// Attach creates a veth pair with the host end `link` attached to the bridge
+// `bridge` and the other end named Interface in the network namespace of
+// the process `pid`. The port of the host end is isolated so the job can
+// only reach the bridge, not the other jobs on it. The veth pair is removed
+// by the kernel once the network namespace is destroyed.
+func Attach(bridge, link string, pid int) error {
+	err := run(
+		"ip", "link", "add", link,
+		"type", "veth",
+		"peer", "name", Interface,
+		"netns", strconv.Itoa(pid),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The port is isolated before it is up so the job cannot reach
+	// the other jobs on the bridge, only the bridge itself.
+	err = run("ip", "link", "set", link, "master", bridge)
+	if err == nil {
+		err = run("ip", "link", "set", link, "type", "bridge_slave", "isolated", "on")
+	}
+
+	if err == nil {
+		err = run("ip", "link", "set", link, "up")
+	}
+
+	if err != nil {
+		_ = run("ip", "link", "del", link)
+		return err
+	}
+
+	return nil
+}
+
+// run executes the command `name` and adds its output to the error.
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"%s %s: %w: %s",
+			name,
+			strings.Join(args, " "),
+			err,
+			strings.TrimSpace(string(out)),
+		)
+	}
+
+	return nil
+}
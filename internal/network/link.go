@@ -0,0 +1,149 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// Configure configures the network namespace of the calling process for
+// `c`. The interfaces are configured using ioctls so the tools of the host
+// are not required within the namespace. Bridged jobs must have been
+// attached using Attach beforehand.
+func Configure(c Config) error {
+	if c.Mode == "" || c.Mode == None {
+		return nil
+	}
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	err = linkUp(fd, "lo")
+	if err != nil || c.Mode != Bridged {
+		return err
+	}
+
+	ip, subnet, err := net.ParseCIDR(c.Address)
+	if err != nil {
+		return err
+	}
+
+	gateway, _, err := net.ParseCIDR(c.Gateway)
+	if err != nil {
+		return err
+	}
+
+	err = setAddr(fd, Interface, syscall.SIOCSIFADDR, ip)
+	if err != nil {
+		return err
+	}
+
+	err = setAddr(fd, Interface, syscall.SIOCSIFNETMASK, net.IP(subnet.Mask))
+	if err != nil {
+		return err
+	}
+
+	err = linkUp(fd, Interface)
+	if err != nil {
+		return err
+	}
+
+	return addDefaultRoute(fd, gateway)
+}
+
+// ifreq is the request of the interface ioctls, see netdevice(7).
+type ifreq struct {
+	name [syscall.IFNAMSIZ]byte
+	data [24]byte
+}
+
+// newIfreq returns a request for the interface `name`.
+func newIfreq(name string) *ifreq {
+	req := &ifreq{}
+	copy(req.name[:syscall.IFNAMSIZ-1], name)
+	return req
+}
+
+// rtentry is the request of the SIOCADDRT ioctl, see route(4).
+type rtentry struct {
+	pad1    uintptr
+	dst     syscall.RawSockaddrInet4
+	gateway syscall.RawSockaddrInet4
+	genmask syscall.RawSockaddrInet4
+	flags   uint16
+	pad2    int16
+	pad3    uintptr
+	pad4    uintptr
+	metric  int16
+	dev     uintptr
+	mtu     uintptr
+	window  uintptr
+	irtt    uint16
+}
+
+// linkUp brings up the interface `name`.
+func linkUp(fd int, name string) error {
+	req := newIfreq(name)
+	err := ioctl(fd, syscall.SIOCGIFFLAGS, unsafe.Pointer(req))
+	if err != nil {
+		return fmt.Errorf("get flags of %s: %w", name, err)
+	}
+
+	flags := (*uint16)(unsafe.Pointer(&req.data[0]))
+	*flags |= syscall.IFF_UP
+
+	err = ioctl(fd, syscall.SIOCSIFFLAGS, unsafe.Pointer(req))
+	if err != nil {
+		return fmt.Errorf("bring up %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// setAddr sets the address selected by the ioctl `op` of the interface
+// `name` to `ip`.
+func setAddr(fd int, name string, op uintptr, ip net.IP) error {
+	req := newIfreq(name)
+
+	addr := (*syscall.RawSockaddrInet4)(unsafe.Pointer(&req.data[0]))
+	addr.Family = syscall.AF_INET
+	copy(addr.Addr[:], ip.To4())
+
+	err := ioctl(fd, op, unsafe.Pointer(req))
+	if err != nil {
+		return fmt.Errorf("set address %s of %s: %w", ip, name, err)
+	}
+
+	return nil
+}
+
+// addDefaultRoute routes all traffic without a more specific route through
+// `gateway`.
+func addDefaultRoute(fd int, gateway net.IP) error {
+	rt := &rtentry{flags: syscall.RTF_UP | syscall.RTF_GATEWAY}
+	rt.dst.Family = syscall.AF_INET
+	rt.genmask.Family = syscall.AF_INET
+	rt.gateway.Family = syscall.AF_INET
+	copy(rt.gateway.Addr[:], gateway.To4())
+
+	err := ioctl(fd, syscall.SIOCADDRT, unsafe.Pointer(rt))
+	if err != nil {
+		return fmt.Errorf("add default route via %s: %w", gateway, err)
+	}
+
+	return nil
+}
+
+// ioctl performs the ioctl `op` with the argument `arg` on `fd`.
+func ioctl(fd int, op uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), op, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package network configures the network namespaces of isolated jobs.
+//
+// Jobs are started in their own network namespace which only contains a
+// loopback interface that is down. The mode of a job selects whether the
+// loopback interface is brought up and whether the job is attached to a
+// bridge on the host through a veth pair.
+package network
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Mode is the network mode of a job.
+type Mode string
+
+const (
+	// None leaves the job without any usable network interface.
+	None Mode = "none"
+
+	// Loopback brings up the loopback interface of the job.
+	Loopback Mode = "loopback"
+
+	// Bridged brings up the loopback interface and attaches the job to
+	// a bridge on the host with a private address. Traffic leaving the
+	// bridge is NATed to the network of the host, while the host and the
+	// other jobs on the bridge cannot be reached.
+	Bridged Mode = "bridged"
+)
+
+// Interface is the name of the interface of a bridged job within its
+// network namespace.
+const Interface = "eth0"
+
+// ErrInvalidMode is returned by ParseMode for unknown network modes.
+var ErrInvalidMode = errors.New("invalid network mode")
+
+// ParseMode returns the mode for `s`. An empty string is the None mode.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", None:
+		return None, nil
+	case Loopback, Bridged:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidMode, s)
+	}
+}
+
+// Config is the network configuration of a job.
+type Config struct {
+	// Mode is the network mode of the job.
+	Mode Mode `json:"mode,omitempty"`
+
+	// Bridge is the bridge on the host the job is attached to and Link
+	// is the name of the host end of the veth pair of the job.
+	Bridge string `json:"bridge,omitempty"`
+	Link   string `json:"link,omitempty"`
+
+	// Address is the address of the job in CIDR notation and Gateway is
+	// the address of the bridge which routes the traffic of the job.
+	Address string `json:"address,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+}
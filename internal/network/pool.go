@@ -0,0 +1,135 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ErrPoolExhausted is returned by Allocate when every address of the pool
+// is in use.
+var ErrPoolExhausted = errors.New("address pool exhausted")
+
+// maxPrefix is the longest prefix of a pool subnet which leaves room for the
+// gateway and at least one job next to the network and broadcast addresses.
+const maxPrefix = 30
+
+// Pool allocates the addresses of bridged jobs from an IPv4 subnet. The
+// first address of the subnet is the gateway which is assigned to the
+// bridge.
+type Pool struct {
+	mu     sync.Mutex
+	subnet *net.IPNet
+	used   map[uint32]bool
+	next   uint32
+}
+
+// NewPool returns a pool for the IPv4 subnet `subnet` in CIDR notation.
+func NewPool(subnet string) (*Pool, error) {
+	_, n, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	ones, bits := n.Mask.Size()
+	if n.IP.To4() == nil || bits != net.IPv4len*8 || ones > maxPrefix {
+		return nil, fmt.Errorf("subnet %s is not an IPv4 subnet with a prefix of at most /%d", subnet, maxPrefix)
+	}
+
+	return &Pool{
+		subnet: n,
+		used:   make(map[uint32]bool),
+	}, nil
+}
+
+// Gateway returns the address of the gateway in CIDR notation.
+func (p *Pool) Gateway() string {
+	return p.cidr(1)
+}
+
+// Subnet returns the subnet of the pool in CIDR notation.
+func (p *Pool) Subnet() string {
+	return p.subnet.String()
+}
+
+// Allocate returns an unused address of the pool in CIDR notation. The
+// addresses are handed out round robin so a released address is not
+// reused right away.
+func (p *Pool) Allocate() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The network address, the gateway and the broadcast
+	// address are not handed out.
+	hosts := p.size() - 3 //nolint:gomnd // network, gateway and broadcast
+	for i := uint32(0); i < hosts; i++ {
+		offset := 2 + (p.next+i)%hosts //nolint:gomnd // first address after the gateway
+		if !p.used[offset] {
+			p.used[offset] = true
+			p.next = (p.next + i + 1) % hosts
+			return p.cidr(offset), nil
+		}
+	}
+
+	return "", ErrPoolExhausted
+}
+
+// Reserve marks the address `addr` in CIDR notation as used, e.g. for the
+// address of a job which was adopted from a previous sandbox.
+func (p *Pool) Reserve(addr string) error {
+	offset, err := p.offset(addr)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.used[offset] = true
+	return nil
+}
+
+// Release returns the address `addr` in CIDR notation to the pool.
+func (p *Pool) Release(addr string) {
+	offset, err := p.offset(addr)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.used, offset)
+}
+
+// size returns the number of addresses of the subnet.
+func (p *Pool) size() uint32 {
+	ones, bits := p.subnet.Mask.Size()
+	return 1 << uint(bits-ones)
+}
+
+// cidr returns the address at `offset` in the subnet in CIDR notation.
+func (p *Pool) cidr(offset uint32) string {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(p.subnet.IP.To4())+offset)
+
+	n := net.IPNet{IP: ip, Mask: p.subnet.Mask}
+	return n.String()
+}
+
+// offset returns the offset of the address `addr` in CIDR notation in the
+// subnet.
+func (p *Pool) offset(addr string) (uint32, error) {
+	ip, _, err := net.ParseCIDR(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	if !p.subnet.Contains(ip) {
+		return 0, fmt.Errorf("address %s is not in subnet %s", addr, p.subnet)
+	}
+
+	return binary.BigEndian.Uint32(ip.To4()) - binary.BigEndian.Uint32(p.subnet.IP.To4()), nil
+}
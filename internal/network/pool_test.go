@@ -0,0 +1,79 @@
+package network
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_NewPool(t *testing.T) {
+	tests := map[string]bool{
+		"10.0.0.0/24":    true,
+		"10.0.0.0/30":    true,
+		"10.0.0.0/31":    false,
+		"fd00::/64":      false,
+		"not-a-subnet":   false,
+		"172.29.0.0/16":  true,
+		"172.29.0.10/16": true,
+	}
+
+	for subnet, valid := range tests {
+		_, err := NewPool(subnet)
+		if valid && err != nil {
+			t.Errorf("expected subnet %s to be valid, got %v", subnet, err)
+		}
+
+		if !valid && err == nil {
+			t.Errorf("expected subnet %s to be invalid", subnet)
+		}
+	}
+}
+
+func Test_Pool(t *testing.T) {
+	p, err := NewPool("10.0.0.0/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Gateway() != "10.0.0.1/29" {
+		t.Fatalf("expected gateway 10.0.0.1/29, got %s", p.Gateway())
+	}
+
+	// Adopted addresses are skipped by Allocate.
+	err = p.Reserve("10.0.0.3/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"10.0.0.2/29", "10.0.0.4/29", "10.0.0.5/29", "10.0.0.6/29"}
+	for _, e := range expected {
+		addr, err := p.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr != e {
+			t.Fatalf("expected address %s, got %s", e, addr)
+		}
+	}
+
+	_, err = p.Allocate()
+	if !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+
+	p.Release("10.0.0.4/29")
+
+	addr, err := p.Allocate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr != "10.0.0.4/29" {
+		t.Fatalf("expected released address 10.0.0.4/29, got %s", addr)
+	}
+
+	err = p.Reserve("10.0.1.2/29")
+	if err == nil {
+		t.Fatal("expected an error for an address outside of the subnet")
+	}
+}
@@ -2,15 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
 	"syscall"
 
+	"go.benjiv.com/sandbox/internal/capture"
 	"go.benjiv.com/sandbox/internal/cgroups"
+	"go.benjiv.com/sandbox/internal/image"
 	"go.benjiv.com/sandbox/internal/iso"
+	"go.benjiv.com/sandbox/internal/network"
 	"go.benjiv.com/sandbox/internal/sig"
+	"go.benjiv.com/sandbox/internal/spec"
 )
 
 // NOTE: I am arbitrarily using an exit code of 2 for helper
@@ -22,6 +29,57 @@ import (
 // subprocess. This is not correct for a production system and
 // would need to be resolved.
 
+// rootlessDenied reports whether `err` is caused by a helper running without
+// root privileges lacking access to the cgroup hierarchy. Rootless helpers
+// only apply resource limits when the cgroup hierarchy is writable.
+func rootlessDenied(err error) bool {
+	if os.Geteuid() == 0 {
+		return false
+	}
+
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// owner maps the owners of the files of the image of the job to the host
+// IDs of the user namespace of the job. IDs which are not mapped are owned
+// by the root of the job.
+func owner(s spec.Spec) image.Owner {
+	if len(s.UIDMap) == 0 && len(s.GIDMap) == 0 {
+		return nil
+	}
+
+	rootUID, _ := iso.HostID(s.UIDMap, 0)
+	rootGID, _ := iso.HostID(s.GIDMap, 0)
+
+	return func(uid, gid int) (int, int) {
+		hostUID, ok := iso.HostID(s.UIDMap, uid)
+		if !ok {
+			hostUID = rootUID
+		}
+
+		hostGID, ok := iso.HostID(s.GIDMap, gid)
+		if !ok {
+			hostGID = rootGID
+		}
+
+		return hostUID, hostGID
+	}
+}
+
+// waitAttached waits until the helper has attached the network namespace
+// of the job to the bridge.
+func waitAttached() error {
+	f := os.NewFile(spec.AttachFD, "attach")
+	defer f.Close()
+
+	_, err := io.ReadFull(f, make([]byte, 1))
+	if err != nil {
+		return fmt.Errorf("network not attached: %w", err)
+	}
+
+	return nil
+}
+
 func usage() {
 	fmt.Println("Usage: prochelper run|sub <cmd> <args>")
 }
@@ -42,24 +100,184 @@ func main() {
 	defer cancel()
 
 	var cmd *exec.Cmd
+	var tty *terminal
+	var started func(pid int)
+	var terminated func(spec.Termination) spec.Termination
 	switch os.Args[1] {
 	case "run": // Isolate the process
-		cmd = iso.Isolate()
+		// Load the job specification before isolating so that the
+		// specification is not passed on to the isolated command.
+		s, err := spec.Load()
+		if err != nil {
+			fail(cancel, err)
+		}
+
+		// Failures of the helper and the termination of the job
+		// are recorded in the result file of the job.
+		report = func(t spec.Termination) {
+			writeResult(s.Result, t)
+		}
+
+		cmd = iso.Isolate(s.UIDMap, s.GIDMap)
+
+		// The isolated helper reports the termination of the
+		// command, or its own failure, over the status pipe which
+		// becomes file descriptor spec.StatusFD.
+		status, reported, err := os.Pipe()
+		if err != nil {
+			fail(cancel, err)
+		}
+		cmd.ExtraFiles = []*os.File{reported}
+
+		started = func(int) {
+			_ = reported.Close()
+		}
+
+		terminated = func(t spec.Termination) spec.Termination {
+			t = collect(status, t)
+
+			// A job killed by the OOM killer of its cgroup
+			// is terminated by SIGKILL.
+			if t.Signal == int(syscall.SIGKILL) {
+				t.OOMKilled = cgroups.OOMKilled(s.CGroup, s.Name)
+			}
+
+			return t
+		}
+
+		// Capture stdout and stderr of the isolated command as
+		// separate streams framed into the output of the helper.
+		w := capture.NewWriter(os.Stdout)
+		if s.OutputLimit > 0 {
+			w = capture.NewLimitedWriter(os.Stdout, s.OutputLimit, s.OutputRing)
+		}
+		cmd.Stdout = w.Stream(capture.Stdout)
+		cmd.Stderr = w.Stream(capture.Stderr)
+
+		// A terminal replaces the stdin, stdout and stderr of the
+		// isolated command and its output is captured as stdout.
+		if s.TTY {
+			tty, err = newTerminal(cmd, w.Stream(capture.Stdout))
+			if err != nil {
+				fail(cancel, err)
+			}
+		}
+
+		err = cgroups.LimitResources(s.CGroup, s.Name, s.Limits)
+		if err != nil && !rootlessDenied(err) {
+			fail(cancel, err)
+		}
+
+		// The image is extracted after the resource limits are
+		// applied so the extraction counts against the job.
+		if s.Image != "" {
+			err = image.Extract(s.Image, s.RootFS, owner(s))
+			if err != nil {
+				fail(cancel, err)
+			}
+		}
+
+		// The root filesystem is passed on to the isolated helper
+		// which changes the root filesystem within the namespaces
+		// of the command. The isolated helper may not be able to
+		// traverse the parent directories of the root filesystem
+		// so it is passed as the inherited working directory.
+		sub := spec.Spec{Network: s.Network, TTY: s.TTY}
+		if s.RootFS != "" {
+			err = iso.MountPoints(s.RootFS)
+			if err != nil {
+				fail(cancel, err)
+			}
 
-		data, err := os.ReadFile("constraints.json")
-		if err == nil {
-			err = cgroups.LimitResources("phelper", data)
+			err = os.Chdir(s.RootFS)
 			if err != nil {
-				cancel()
-				os.Exit(2)
+				fail(cancel, err)
 			}
+
+			sub.RootFS = "."
 		}
+
+		// Bridged jobs are attached to the bridge once the isolated
+		// helper has created the network namespace of the job. The
+		// isolated helper waits until the attachment is signaled.
+		if s.Network.Mode == network.Bridged {
+			attached, ready, err := os.Pipe()
+			if err != nil {
+				fail(cancel, err)
+			}
+
+			// The pipe becomes file descriptor spec.AttachFD
+			// in the isolated helper.
+			cmd.ExtraFiles = append(cmd.ExtraFiles, attached)
+			closeReported := started
+			started = func(pid int) {
+				closeReported(pid)
+				_ = attached.Close()
+				defer ready.Close()
+
+				// A failed attachment closes the pipe without
+				// signaling, which fails the isolated helper.
+				err := network.Attach(s.Network.Bridge, s.Network.Link, pid)
+				if err == nil {
+					_, _ = ready.Write([]byte{1})
+				}
+			}
+		}
+
+		env, err := sub.Environ()
+		if err != nil {
+			fail(cancel, err)
+		}
+		cmd.Env = append(os.Environ(), env)
 	case "sub": // Run the command provided as an argument
+		// The status pipe must not be inherited by the command
+		// so the helper reads the end of the pipe once the
+		// isolated helper exits.
+		syscall.CloseOnExec(spec.StatusFD)
+		status := os.NewFile(spec.StatusFD, "status")
+		report = func(t spec.Termination) {
+			_ = json.NewEncoder(status).Encode(t)
+		}
+
+		s, err := spec.Load()
+		if err != nil && err != spec.ErrMissing {
+			fail(cancel, err)
+		}
+
+		if s.Network.Mode == network.Bridged {
+			err = waitAttached()
+			if err != nil {
+				fail(cancel, err)
+			}
+		}
+
+		err = network.Configure(s.Network)
+		if err != nil {
+			fail(cancel, err)
+		}
+
+		if s.RootFS != "" {
+			err = iso.PivotRoot(s.RootFS)
+			if err != nil {
+				fail(cancel, err)
+			}
+		}
+
 		// nolint:gosec
 		cmd = exec.Command(os.Args[2], os.Args[3:]...)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
+
+		// The command leads its own process group so signals can
+		// be sent to the command and its children without
+		// reaching the helpers. The process group of a command
+		// with a terminal becomes the foreground process group
+		// of the terminal, which is its stdin.
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			Setpgid:    true,
+			Foreground: s.TTY,
+		}
 	default:
 		usage()
 		os.Exit(1)
@@ -68,8 +286,15 @@ func main() {
 	// Initiate the command
 	err := cmd.Start()
 	if err != nil {
-		cancel()
-		os.Exit(2)
+		fail(cancel, err)
+	}
+
+	if started != nil {
+		started(cmd.Process.Pid)
+	}
+
+	if tty != nil {
+		tty.relay()
 	}
 
 	// Cascade sigterm to the child processes
@@ -77,19 +302,63 @@ func main() {
 	go func() {
 		<-ctx.Done()
 
-		// Send terminate / kill
-		err = sig.Term(cmd)
-		if err != nil {
-			os.Exit(2)
-		}
+		// Send terminate / kill. The error is ignored since
+		// the signal only fails when the command already
+		// exited, in which case its termination is reported
+		// once it is waited on.
+		_ = sig.Term(cmd)
 	}()
 
 	// Wait for the child process to exit
 	// Wait populates the ProcessState
-	err = cmd.Wait()
-	if exitError, ok := err.(*exec.ExitError); ok {
-		os.Exit(exitError.ExitCode())
+	_ = cmd.Wait()
+
+	// Ensure all of the terminal output is captured before exiting.
+	if tty != nil {
+		tty.wait()
+	}
+
+	t := spec.Terminated(cmd.ProcessState)
+	if terminated != nil {
+		t = terminated(t)
+	}
+
+	report(t)
+	os.Exit(t.Code)
+}
+
+// report records the termination of the job. It is set by the mode of the
+// helper once the destination of the termination is known.
+var report = func(spec.Termination) {}
+
+// fail reports that the helper failed with `err` and exits.
+func fail(cancel context.CancelFunc, err error) {
+	cancel()
+	report(spec.Failed(err))
+	os.Exit(spec.FailedExit)
+}
+
+// collect returns the termination reported by the isolated helper over
+// the status pipe. The termination of the isolated helper itself, `t`, is
+// used when the isolated helper exited without reporting, e.g. because it
+// was killed.
+func collect(status io.Reader, t spec.Termination) spec.Termination {
+	var reported spec.Termination
+	err := json.NewDecoder(status).Decode(&reported)
+	if err != nil {
+		return t
+	}
+
+	return reported
+}
+
+// writeResult records the termination of the job for a sandbox which
+// re-adopts the process.
+func writeResult(path string, t spec.Termination) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
 	}
 
-	os.Exit(0)
+	_ = os.WriteFile(path, data, 0600)
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/iso"
+	"go.benjiv.com/sandbox/internal/pty"
+	"go.benjiv.com/sandbox/internal/spec"
+)
+
+// drainTimeout is how long the output of the terminal is drained after
+// the isolated command has exited.
+const drainTimeout = time.Millisecond * 50
+
+// terminal relays a pseudo-terminal allocated for the isolated command.
+type terminal struct {
+	master *os.File
+	slave  *os.File
+	out    io.Writer
+	done   chan struct{}
+}
+
+// newTerminal allocates a pseudo-terminal and attaches the isolated command
+// to it. The output of the terminal is written to `out`.
+func newTerminal(cmd *exec.Cmd, out io.Writer) (*terminal, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	iso.Terminal(cmd, slave)
+
+	return &terminal{
+		master: master,
+		slave:  slave,
+		out:    out,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// relay starts relaying the terminal after the isolated command has
+// started. Keystrokes from the stdin of the helper are written to the
+// terminal, the output of the terminal is captured and resize events
+// from the control pipe are applied to the terminal.
+func (t *terminal) relay() {
+	// NOTE: The slave end is kept open by the helper because
+	// reading the master end can fail with EIO before all of the
+	// buffered output is read once every slave end is closed.
+	go func() {
+		defer close(t.done)
+
+		_, _ = io.Copy(t.out, t.master)
+	}()
+
+	go func() {
+		_, _ = io.Copy(t.master, os.Stdin)
+	}()
+
+	go func() {
+		control := os.NewFile(spec.ControlFD, "control")
+		for {
+			size, err := pty.ReadSize(control)
+			if err != nil {
+				return
+			}
+
+			_ = pty.Resize(t.master, size)
+		}
+	}()
+}
+
+// wait blocks until all of the output of the terminal is captured. It
+// is called after the isolated command has exited.
+func (t *terminal) wait() {
+	// The output still buffered in the terminal is drained
+	// before the relay is stopped by the read deadline.
+	err := t.master.SetReadDeadline(time.Now().Add(drainTimeout))
+	if err != nil {
+		_ = t.slave.Close()
+	}
+
+	<-t.done
+
+	_ = t.slave.Close()
+	_ = t.master.Close()
+}
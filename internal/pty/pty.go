@@ -0,0 +1,45 @@
+package pty
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrUnsupported is returned on platforms without pseudo-terminal support.
+var ErrUnsupported = errors.New("terminals are not supported on this platform")
+
+// sizeLen is the encoded length of a Size on the control pipe.
+const sizeLen = 4
+
+// Size is the size of a terminal in character cells.
+type Size struct {
+	Rows uint16
+	Cols uint16
+}
+
+// WriteSize encodes the size onto the control pipe of the helper. The
+// encoded size is smaller than PIPE_BUF so concurrent writes are atomic.
+func WriteSize(w io.Writer, s Size) error {
+	buf := make([]byte, sizeLen)
+	binary.BigEndian.PutUint16(buf[0:2], s.Rows)
+	binary.BigEndian.PutUint16(buf[2:4], s.Cols)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadSize decodes the next size from the control pipe of the helper.
+func ReadSize(r io.Reader) (Size, error) {
+	buf := make([]byte, sizeLen)
+
+	_, err := io.ReadFull(r, buf)
+	if err != nil {
+		return Size{}, err
+	}
+
+	return Size{
+		Rows: binary.BigEndian.Uint16(buf[0:2]),
+		Cols: binary.BigEndian.Uint16(buf[2:4]),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package pty
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// winsize mirrors `struct winsize` from the kernel.
+type winsize struct {
+	rows   uint16
+	cols   uint16
+	xpixel uint16
+	ypixel uint16
+}
+
+// ioctl executes the ioctl request `req` against the file. The file
+// descriptor is accessed through SyscallConn rather than Fd so the file
+// stays in non-blocking mode and supports read deadlines.
+func ioctl(f *os.File, req, arg uintptr) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg)
+	})
+	if err != nil {
+		return err
+	}
+
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// Open allocates a new pseudo-terminal and returns its master and slave
+// ends. The slave end is intended to become the controlling terminal of
+// the isolated command while the master end is relayed by the helper.
+func Open() (master, slave *os.File, err error) {
+	master, err = os.OpenFile(
+		"/dev/ptmx",
+		os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC,
+		0,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = master.Close()
+		}
+	}()
+
+	// Unlock the slave end so it can be opened.
+	var unlock int32
+	err = ioctl(
+		master,
+		syscall.TIOCSPTLCK,
+		uintptr(unsafe.Pointer(&unlock)), //nolint:gosec // ioctl argument
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var n uint32
+	err = ioctl(
+		master,
+		syscall.TIOCGPTN,
+		uintptr(unsafe.Pointer(&n)), //nolint:gosec // ioctl argument
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slave, err = os.OpenFile(
+		"/dev/pts/"+strconv.Itoa(int(n)),
+		os.O_RDWR|syscall.O_NOCTTY,
+		0,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return master, slave, nil
+}
+
+// Resize sets the size of the terminal which sends SIGWINCH to the
+// foreground process group of the terminal.
+func Resize(f *os.File, s Size) error {
+	ws := winsize{rows: s.Rows, cols: s.Cols}
+
+	return ioctl(
+		f,
+		syscall.TIOCSWINSZ,
+		uintptr(unsafe.Pointer(&ws)), //nolint:gosec // ioctl argument
+	)
+}
+
+// GetSize returns the size of the terminal.
+func GetSize(f *os.File) (Size, error) {
+	ws := winsize{}
+
+	err := ioctl(
+		f,
+		syscall.TIOCGWINSZ,
+		uintptr(unsafe.Pointer(&ws)), //nolint:gosec // ioctl argument
+	)
+	if err != nil {
+		return Size{}, err
+	}
+
+	return Size{Rows: ws.rows, Cols: ws.cols}, nil
+}
+
+// MakeRaw puts the terminal into raw mode, following cfmakeraw(3), and
+// returns a function which restores the previous mode.
+func MakeRaw(f *os.File) (restore func() error, err error) {
+	var old syscall.Termios
+	err = ioctl(
+		f,
+		syscall.TCGETS,
+		uintptr(unsafe.Pointer(&old)), //nolint:gosec // ioctl argument
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK |
+		syscall.ISTRIP | syscall.INLCR | syscall.IGNCR |
+		syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON |
+		syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	err = ioctl(
+		f,
+		syscall.TCSETS,
+		uintptr(unsafe.Pointer(&raw)), //nolint:gosec // ioctl argument
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return ioctl(
+			f,
+			syscall.TCSETS,
+			uintptr(unsafe.Pointer(&old)), //nolint:gosec // ioctl argument
+		)
+	}, nil
+}
+
+// NotifyResize relays SIGWINCH, sent when the size of the terminal of
+// this process changes, to `c`.
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
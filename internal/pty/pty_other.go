@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+package pty
+
+import (
+	"os"
+)
+
+// Open is not supported on this platform.
+func Open() (master, slave *os.File, err error) {
+	return nil, nil, ErrUnsupported
+}
+
+// Resize is not supported on this platform.
+func Resize(f *os.File, s Size) error {
+	return ErrUnsupported
+}
+
+// GetSize is not supported on this platform.
+func GetSize(f *os.File) (Size, error) {
+	return Size{}, ErrUnsupported
+}
+
+// MakeRaw is not supported on this platform.
+func MakeRaw(f *os.File) (restore func() error, err error) {
+	return nil, ErrUnsupported
+}
+
+// NotifyResize is a no-op on this platform.
+func NotifyResize(c chan<- os.Signal) {}
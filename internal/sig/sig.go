@@ -2,6 +2,7 @@ package sig
 
 import (
 	"errors"
+	"os"
 	"os/exec"
 	"syscall"
 )
@@ -14,9 +15,20 @@ func Term(cmd *exec.Cmd) error {
 		return errors.New("cmd is nil")
 	}
 
-	err := cmd.Process.Signal(syscall.SIGTERM)
+	return TermProcess(cmd.Process)
+}
+
+// TermProcess sends a SIGTERM signal to the process
+// to exit gracefully, if that fails then the
+// process is killed
+func TermProcess(p *os.Process) error {
+	if p == nil {
+		return errors.New("process is nil")
+	}
+
+	err := p.Signal(syscall.SIGTERM)
 	if err != nil {
-		err := cmd.Process.Kill()
+		err := p.Kill()
 		if err != nil {
 			return err
 		}
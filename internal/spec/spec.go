@@ -0,0 +1,157 @@
+package spec
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"syscall"
+
+	"go.benjiv.com/sandbox/internal/cgroups"
+	"go.benjiv.com/sandbox/internal/iso"
+	"go.benjiv.com/sandbox/internal/network"
+)
+
+// Env is the environment variable used to pass the job specification from
+// the sandbox library to the helper binary.
+const Env = "SANDBOX_SPEC"
+
+// ControlFD is the file descriptor of the control pipe which is passed to
+// the helper for jobs with a terminal. Terminal resize events are sent to
+// the helper over the control pipe.
+const ControlFD = 3
+
+// StatusFD is the file descriptor of the pipe which is passed to the
+// isolated helper. The isolated helper reports the Termination of the
+// command, or its own failure, to the helper over the pipe.
+const StatusFD = 3
+
+// AttachFD is the file descriptor of the pipe which is passed to the
+// isolated helper of a bridged job. The helper writes a single byte to
+// the pipe once the job is attached to the bridge.
+const AttachFD = 4
+
+// FailedExit is the exit code of a helper which failed to set up or start
+// the job.
+const FailedExit = 2
+
+// signalExit is the base of the exit code of a job which was terminated
+// by a signal, following the convention of shells.
+const signalExit = 128
+
+// Termination describes how a job terminated. It is recorded by the helper
+// in the result file of the job.
+type Termination struct {
+	// Code is the exit code of the job. Jobs which were terminated by a
+	// signal have the exit code 128 plus the signal number.
+	Code int `json:"code"`
+
+	// Signal is the signal which terminated the job.
+	Signal int `json:"signal,omitempty"`
+
+	// OOMKilled reports that the job was killed by the OOM killer of its
+	// cgroup.
+	OOMKilled bool `json:"oom_killed,omitempty"`
+
+	// Error is the error of a helper which failed to set up or start the
+	// job.
+	Error string `json:"error,omitempty"`
+}
+
+// Terminated returns the Termination of a process from its wait status.
+func Terminated(state *os.ProcessState) Termination {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if ok && ws.Signaled() {
+		return Termination{
+			Code:   signalExit + int(ws.Signal()),
+			Signal: int(ws.Signal()),
+		}
+	}
+
+	return Termination{Code: state.ExitCode()}
+}
+
+// Failed returns the Termination of a job whose helper failed with `err`.
+func Failed(err error) Termination {
+	return Termination{Code: FailedExit, Error: err.Error()}
+}
+
+// ErrMissing is returned by Load when the helper was started without a job
+// specification.
+var ErrMissing = errors.New("missing job specification")
+
+// Spec is the per-job configuration the helper binary applies before
+// executing the isolated command.
+type Spec struct {
+	// CGroup is the parent cgroup which contains all jobs of a sandbox.
+	CGroup string `json:"cgroup"`
+
+	// Name is the name of the job's cgroup under the parent cgroup.
+	Name string `json:"name"`
+
+	// Limits are the resource limits applied to the job's cgroup.
+	Limits cgroups.Limits `json:"limits"`
+
+	// TTY allocates a pseudo-terminal for the job.
+	TTY bool `json:"tty,omitempty"`
+
+	// Result is the path of the file the helper writes the exit code
+	// of the job to, so the exit code is available to a sandbox which
+	// did not start the helper.
+	Result string `json:"result,omitempty"`
+
+	// RootFS is the directory which becomes the root filesystem of the
+	// job. When empty the job uses the root filesystem of the host.
+	RootFS string `json:"rootfs,omitempty"`
+
+	// Image is a tarball which is extracted into RootFS before the job
+	// is started.
+	Image string `json:"image,omitempty"`
+
+	// UIDMap and GIDMap map the user and group IDs of the user namespace
+	// of the job to the host. When empty the job shares the user
+	// namespace of the host.
+	UIDMap []iso.IDMap `json:"uid_map,omitempty"`
+	GIDMap []iso.IDMap `json:"gid_map,omitempty"`
+
+	// Network is the network configuration of the job.
+	Network network.Config `json:"network,omitempty"`
+
+	// OutputLimit is the number of bytes of output retained for the
+	// job, or zero when the output is not limited. The most recent
+	// output is retained by a ring, otherwise the output is truncated.
+	OutputLimit int64 `json:"output_limit,omitempty"`
+	OutputRing  bool  `json:"output_ring,omitempty"`
+}
+
+// Environ encodes the specification as an environment variable entry in
+// the `key=value` format expected by `exec.Cmd.Env`.
+func (s Spec) Environ() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	return Env + "=" + string(data), nil
+}
+
+// Load decodes the specification from the environment and removes it so
+// that it is not inherited by the isolated command.
+func Load() (Spec, error) {
+	data, ok := os.LookupEnv(Env)
+	if !ok {
+		return Spec{}, ErrMissing
+	}
+
+	err := os.Unsetenv(Env)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	s := Spec{}
+	err = json.Unmarshal([]byte(data), &s)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	return s, nil
+}
@@ -1,7 +1,137 @@
 package tls
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Commands is the allow-list of the commands of a role.
 type Commands map[string]bool
-type UnitRoles map[string]Commands
+
+// Role is the configuration of a unit of an organization. Beside the
+// allow-list of commands, the typed fields grant the permissions which
+// are not commands.
+type Role struct {
+	Commands Commands `json:"commands"`
+
+	// AnyJob allows the clients of the role to act on the processes
+	// of other clients.
+	AnyJob bool `json:"any_job,omitempty"`
+
+	// Network lists the network modes the commands of the clients of
+	// the role may use, besides no network access.
+	Network []string `json:"network,omitempty"`
+
+	// Deadline is the maximum runtime of the commands of the clients
+	// of the role. Zero does not limit the runtime.
+	Deadline Duration `json:"deadline,omitempty"`
+
+	// Signals lists the signals the clients of the role may send to
+	// processes, by their name, e.g. "SIGHUP", or by their number when
+	// they have no name.
+	Signals []string `json:"signals,omitempty"`
+
+	// Images lists the images of the server the commands of the
+	// clients of the role may use as their root filesystem.
+	Images []string `json:"images,omitempty"`
+}
+
+// roleFields are the fields of a role, which tell a role apart from the
+// flat allow-list of commands that roles were configured with before the
+// typed fields.
+var roleFields = []string{"commands", "any_job", "network", "deadline", "signals", "images"}
+
+// UnmarshalJSON decodes the role. Roles configured as a flat allow-list of
+// commands are still accepted, and their entries which granted a permission
+// which is not a command, "jobs:any", "network:<mode>", "deadline:<duration>"
+// and "signal:<name>", are moved to the typed fields.
+func (r *Role) UnmarshalJSON(data []byte) error {
+	fields := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range roleFields {
+		if _, ok := fields[field]; ok {
+			// The plain type decodes the fields without
+			// calling UnmarshalJSON again.
+			type plain Role
+			return json.Unmarshal(data, (*plain)(r))
+		}
+	}
+
+	commands := Commands{}
+	err = json.Unmarshal(data, &commands)
+	if err != nil {
+		return fmt.Errorf("role is neither a role nor a list of commands: %w", err)
+	}
+
+	*r, err = flatRole(commands)
+	return err
+}
+
+// flatRole converts the flat allow-list of commands of a role to a role.
+func flatRole(commands Commands) (Role, error) {
+	r := Role{Commands: Commands{}}
+
+	for entry, allowed := range commands {
+		if !allowed {
+			continue
+		}
+
+		switch {
+		case entry == "jobs:any":
+			r.AnyJob = true
+		case strings.HasPrefix(entry, "network:"):
+			r.Network = append(r.Network, strings.TrimPrefix(entry, "network:"))
+		case strings.HasPrefix(entry, "signal:"):
+			r.Signals = append(r.Signals, strings.TrimPrefix(entry, "signal:"))
+		case strings.HasPrefix(entry, "deadline:"):
+			d, err := time.ParseDuration(strings.TrimPrefix(entry, "deadline:"))
+			if err != nil || d < 0 {
+				return Role{}, fmt.Errorf("invalid deadline entry %q", entry)
+			}
+
+			if Duration(d) > r.Deadline {
+				r.Deadline = Duration(d)
+			}
+		default:
+			r.Commands[entry] = true
+		}
+	}
+
+	return r, nil
+}
+
+// Duration is a duration which is encoded as a string, e.g. "1h", see
+// time.ParseDuration.
+type Duration time.Duration
+
+// UnmarshalJSON decodes the duration from a string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	if v < 0 {
+		return fmt.Errorf("negative duration %q", s)
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+type UnitRoles map[string]Role
 type OrgRoles map[string]UnitRoles
 
 // GetCommands negotiates the available commands by combining the certificate
@@ -15,21 +145,82 @@ type OrgRoles map[string]UnitRoles
 // has roles pre-pendend with the org name. Ex. org: "hr", unit: "hr.admin"
 // would allow for proper fine-grained control.
 func GetCommands(config OrgRoles, orgs, units []string) Commands {
-	available := Commands{}
+	return GetRole(config, orgs, units).Commands
+}
 
-	for _, org := range orgs {
-		if len(units) == 0 {
-			continue
-		}
+// GetRole negotiates the role of a certificate by combining the roles of its
+// organizations and units the same way as GetCommands. A permission granted
+// by any of the roles is granted by the combined role, and the deadline of
+// the combined role is the largest deadline of the roles. A role without a
+// deadline does not limit the runtime, so the combined role is not limited
+// either.
+func GetRole(config OrgRoles, orgs, units []string) Role {
+	role := Role{Commands: Commands{}}
+	unlimited := false
 
+	for _, org := range orgs {
 		for _, unit := range units {
-			for command, allowed := range config[org][unit] {
+			r, ok := config[org][unit]
+			if !ok {
+				continue
+			}
+
+			for command, allowed := range r.Commands {
 				if allowed {
-					available[command] = true
+					role.Commands[command] = true
 				}
 			}
+
+			role.AnyJob = role.AnyJob || r.AnyJob
+
+			if r.Deadline == 0 {
+				unlimited = true
+			} else if r.Deadline > role.Deadline {
+				role.Deadline = r.Deadline
+			}
+
+			role.Network = append(role.Network, r.Network...)
+			role.Signals = append(role.Signals, r.Signals...)
+			role.Images = append(role.Images, r.Images...)
+		}
+	}
+
+	if unlimited {
+		role.Deadline = 0
+	}
+
+	return role
+}
+
+// AllowsNetwork reports whether the role allows the network mode.
+func (r Role) AllowsNetwork(mode string) bool {
+	for _, m := range r.Network {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsImage reports whether the role allows the image with the name.
+func (r Role) AllowsImage(name string) bool {
+	for _, i := range r.Images {
+		if i == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsSignal reports whether the role allows the signal with the name.
+func (r Role) AllowsSignal(name string) bool {
+	for _, s := range r.Signals {
+		if s == name {
+			return true
 		}
 	}
 
-	return available
+	return false
 }
@@ -0,0 +1,92 @@
+package tls
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_GetRole_deadline(t *testing.T) {
+	config := OrgRoles{
+		"it": {
+			"admin": {Commands: Commands{"*": true}},
+			"user":  {Commands: Commands{"ls": true}, Deadline: Duration(time.Hour)},
+			"ops":   {Commands: Commands{"ps": true}, Deadline: Duration(time.Hour * 2)},
+		},
+	}
+
+	tests := map[string]struct {
+		units    []string
+		expected time.Duration
+	}{
+		"limited":             {[]string{"user"}, time.Hour},
+		"largest deadline":    {[]string{"user", "ops"}, time.Hour * 2},
+		"unlimited":           {[]string{"admin"}, 0},
+		"unlimited wins":      {[]string{"user", "admin"}, 0},
+		"unlimited any order": {[]string{"admin", "ops", "user"}, 0},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			role := GetRole(config, []string{"it"}, test.units)
+			if time.Duration(role.Deadline) != test.expected {
+				t.Fatalf("expected deadline %s, got %s", test.expected, time.Duration(role.Deadline))
+			}
+		})
+	}
+}
+
+func Test_Role_UnmarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		expected Role
+	}{
+		"typed": {
+			`{"commands": {"ls": true, "ps": false}, "network": ["loopback"], "deadline": "1h"}`,
+			Role{Commands: Commands{"ls": true, "ps": false}, Network: []string{"loopback"}, Deadline: Duration(time.Hour)},
+		},
+		"typed without commands": {
+			`{"any_job": true}`,
+			Role{AnyJob: true},
+		},
+		"flat": {
+			`{"ls": true, "ps": false}`,
+			Role{Commands: Commands{"ls": true}},
+		},
+		"flat permissions": {
+			`{"ls": true, "jobs:any": true, "network:loopback": true, "deadline:1h": true, "signal:SIGHUP": true}`,
+			Role{
+				Commands: Commands{"ls": true},
+				AnyJob:   true,
+				Network:  []string{"loopback"},
+				Deadline: Duration(time.Hour),
+				Signals:  []string{"SIGHUP"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			r := Role{}
+			err := json.Unmarshal([]byte(test.config), &r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(r, test.expected) {
+				t.Fatalf("expected %+v, got %+v", test.expected, r)
+			}
+		})
+	}
+
+	for _, config := range []string{`{"ls": "yes"}`, `{"deadline:forever": true}`, `{"deadline": 5}`, `[]`} {
+		r := Role{}
+		err := json.Unmarshal([]byte(config), &r)
+		if err == nil {
+			t.Fatalf("expected an error for %s, got %+v", config, r)
+		}
+	}
+}
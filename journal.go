@@ -0,0 +1,459 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/spec"
+)
+
+// NOTE: The naming of the prefixes here is to keep them from being
+// exported to users of the library since they are not part of the
+// public API.
+const (
+	// journalPrefix is the prefix of the journal entry of a process.
+	journalPrefix = "job-"
+
+	// resultPrefix is the prefix of the file the helper writes the
+	// termination of the process to.
+	resultPrefix = "result-"
+
+	// rootfsPrefix is the prefix of the directory the image of the
+	// process is extracted to.
+	rootfsPrefix = "rootfs-"
+
+	// cgroupFile is the file holding the name of the parent cgroup of
+	// the processes of the Box, and cgroupPrefix the prefix of the
+	// name.
+	cgroupFile   = "cgroup"
+	cgroupPrefix = "sandbox-"
+
+	// lockFile is the file locked by the Box using the journal.
+	lockFile = "lock"
+
+	// unknownExit is the exit code of a process which exited without
+	// the helper recording the exit code, e.g. the helper was killed.
+	unknownExit = -1
+)
+
+// record is the journal entry of a process. It holds everything needed
+// to serve the process after the Box which started it is gone.
+type record struct {
+	ID      string   `json:"id"`
+	Alias   int64    `json:"alias"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	TTY     bool     `json:"tty,omitempty"`
+
+	// Identity is the identity of the requester of the process, see
+	// WithIdentity.
+	Identity string `json:"identity,omitempty"`
+
+	// Output is the path of the captured output of the process and
+	// Result is the path of the termination written by the helper.
+	Output string `json:"output"`
+	Result string `json:"result"`
+
+	// RootFS is the directory the image of the process was extracted
+	// to, if the process was started with an image.
+	RootFS string `json:"rootfs,omitempty"`
+
+	// Address is the address of the process on the bridge of the Box,
+	// if the process was started with NetworkBridged.
+	Address string `json:"address,omitempty"`
+
+	// PID is the process ID of the helper and Started is the start
+	// time of the helper in clock ticks since boot. The start time is
+	// used to detect when the PID was reused by another process.
+	PID     int    `json:"pid"`
+	Started uint64 `json:"started"`
+
+	// JobPID is the PID of the process inside its PID namespace and
+	// Start is the time the helper was started.
+	JobPID int       `json:"job_pid,omitempty"`
+	Start  time.Time `json:"start,omitempty"`
+
+	// Stopped records that the process was stopped using Box.Stop and
+	// TimedOut that it was stopped because it exceeded its Deadline, so
+	// its termination is not reported as a signal.
+	Stopped  bool      `json:"stopped,omitempty"`
+	TimedOut bool      `json:"timed_out,omitempty"`
+	Deadline time.Time `json:"deadline,omitempty"`
+
+	// Paused records that the processes of the process are frozen, see
+	// Box.Pause.
+	Paused bool `json:"paused,omitempty"`
+
+	// OutputLimit is the number of bytes of output retained for the
+	// process, and OutputRing whether the most recent output is
+	// retained, see WithOutputLimit.
+	OutputLimit int64 `json:"output_limit,omitempty"`
+	OutputRing  bool  `json:"output_ring,omitempty"`
+
+	Exited   bool      `json:"exited"`
+	Code     int       `json:"code"`
+	Reason   Reason    `json:"reason,omitempty"`
+	Signal   int       `json:"signal,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// terminate records the termination `t` of the process at `finished`.
+func (r *record) terminate(t spec.Termination, finished time.Time) {
+	r.Exited = true
+	r.Paused = false
+	r.Code = t.Code
+	r.Signal = t.Signal
+	r.Error = t.Error
+	r.Finished = finished
+
+	switch {
+	case t.Error != "":
+		r.Reason = ReasonFailed
+	case t.OOMKilled:
+		r.Reason = ReasonOOMKilled
+	case r.TimedOut:
+		r.Reason = ReasonTimedOut
+	case r.Stopped:
+		r.Reason = ReasonStopped
+	case t.Signal != 0:
+		r.Reason = ReasonSignaled
+	default:
+		r.Reason = ReasonExited
+	}
+}
+
+// status returns the Status of the process.
+func (r record) status() Status {
+	s := Status{
+		Command:   r.Command,
+		Args:      r.Args,
+		Identity:  r.Identity,
+		PID:       r.PID,
+		JobPID:    r.JobPID,
+		Started:   r.Start,
+		Deadline:  r.Deadline,
+		Paused:    r.Paused,
+		Truncated: r.truncated(),
+		Exited:    r.Exited,
+		Code:      r.Code,
+		Reason:    r.Reason,
+		Signal:    syscall.Signal(r.Signal),
+		Error:     r.Error,
+	}
+
+	if r.Exited {
+		s.Finished = r.Finished
+	}
+
+	// Processes recorded by earlier versions have no start time.
+	switch {
+	case r.Start.IsZero():
+	case r.Exited:
+		s.Duration = r.Finished.Sub(r.Start)
+	default:
+		s.Duration = time.Since(r.Start)
+	}
+
+	return s
+}
+
+// journal is the on-disk catalog of the processes of a Box. Every
+// process has its own entry which is replaced atomically on update.
+type journal struct {
+	dir string
+}
+
+// path returns the path of the journal entry for the given id.
+func (j journal) path(id string) string {
+	return filepath.Join(j.dir, journalPrefix+id+".json")
+}
+
+// write creates or replaces the journal entry of the process.
+func (j journal) write(rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path(rec.ID) + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, j.path(rec.ID))
+}
+
+// remove deletes the journal entry of the process.
+func (j journal) remove(id string) error {
+	err := os.Remove(j.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// lock locks the journal for the Box using it, and returns the file which
+// holds the lock until it is closed. The lock is released by the kernel
+// when the process exits, so the journal of a server which stopped is not
+// left locked. ErrStateDirLocked is returned when another Box holds it.
+func (j journal) lock() (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(j.dir, lockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		_ = f.Close()
+		return nil, fmt.Errorf("%w: %s", ErrStateDirLocked, j.dir)
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// cgroup returns the name of the parent cgroup of the processes of the
+// journal. The name is random so Boxes never share their cgroups, and is
+// persisted so the processes are found in their cgroups once they are
+// re-adopted. Journals holding processes started before the name was
+// persisted keep the name of their directory, which was used until then.
+func (j journal) cgroup() (string, error) {
+	path := filepath.Join(j.dir, cgroupFile)
+
+	data, err := os.ReadFile(path)
+	if err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return string(bytes.TrimSpace(data)), nil
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	recs, err := j.load()
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(j.dir)
+	if len(recs) == 0 {
+		id, err := newID()
+		if err != nil {
+			return "", err
+		}
+
+		name = cgroupPrefix + id
+	}
+
+	err = os.WriteFile(path+".tmp", []byte(name), 0600)
+	if err != nil {
+		return "", err
+	}
+
+	return name, os.Rename(path+".tmp", path)
+}
+
+// load reads all of the journal entries. Entries which cannot be decoded
+// are skipped since they cannot be served.
+func (j journal) load() ([]record, error) {
+	matches, err := filepath.Glob(filepath.Join(j.dir, journalPrefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []record
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+
+		rec := record{}
+		err = json.Unmarshal(data, &rec)
+		if err != nil || rec.ID == "" {
+			continue
+		}
+
+		recs = append(recs, rec)
+	}
+
+	return recs, nil
+}
+
+// readResult returns the termination written by the helper and whether
+// the helper wrote one. Results holding a plain exit code, as written by
+// earlier helpers, are read as a termination with that exit code.
+func readResult(path string) (spec.Termination, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return spec.Termination{}, false
+	}
+
+	t := spec.Termination{}
+	if json.Unmarshal(data, &t) == nil {
+		return t, true
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return spec.Termination{}, false
+	}
+
+	return spec.Termination{Code: code}, true
+}
+
+// result returns the termination of the process recorded by the helper in
+// the result file `path`. When the helper exited without recording one,
+// e.g. because it was killed, the termination is derived from the wait
+// status of the helper, `state`, if it is known.
+func result(path string, state *os.ProcessState) spec.Termination {
+	if t, ok := readResult(path); ok {
+		return t
+	}
+
+	if state == nil {
+		return spec.Termination{
+			Code:  unknownExit,
+			Error: "helper exited without recording the termination",
+		}
+	}
+
+	t := spec.Terminated(state)
+	if t.Signal == 0 {
+		t.Error = fmt.Sprintf("helper exited with code %d without recording the termination", t.Code)
+	}
+
+	return t
+}
+
+// procStat returns the state and the start time, in clock ticks since
+// boot, of the process as reported by /proc/<pid>/stat.
+func procStat(pid int) (state byte, started uint64, err error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The command name is wrapped in parentheses and may contain spaces
+	// so the fields are counted from the closing parenthesis. The state
+	// is the 3rd field and the start time is the 22nd field.
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0, 0, errors.New("malformed process stat")
+	}
+
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 { //nolint:gomnd // field count of /proc/<pid>/stat
+		return 0, 0, errors.New("malformed process stat")
+	}
+
+	started, err = strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fields[0][0], started, nil
+}
+
+// truncated reports whether output of the process was discarded because
+// it reached its output limit. The records of the output reach beyond the
+// limit once output is discarded, including the output discarded by a
+// ring which keeps the offsets of the retained output.
+func (r record) truncated() bool {
+	if r.OutputLimit == 0 {
+		return false
+	}
+
+	info, err := os.Stat(r.Output)
+	return err == nil && info.Size() > r.OutputLimit
+}
+
+// hostJobPID returns the PID of the process of the helper `pid` on the
+// host, or zero when the process is not running. The process is the child
+// of the isolated helper which is the child of the helper.
+func hostJobPID(pid int) int {
+	for i := 0; i < 2; i++ { //nolint:gomnd // the isolated helper and the process
+		pid = firstChild(pid)
+		if pid == 0 {
+			return 0
+		}
+	}
+
+	return pid
+}
+
+// jobPID returns the PID of the process of the helper `pid` inside its PID
+// namespace, or zero when the process is not running.
+func jobPID(pid int) int {
+	pid = hostJobPID(pid)
+	if pid == 0 {
+		return 0
+	}
+
+	// The NSpid field lists the PID of the process in every PID
+	// namespace it is in, ending with the innermost namespace.
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[0] == "NSpid:" {
+			nspid, _ := strconv.Atoi(fields[len(fields)-1])
+			return nspid
+		}
+	}
+
+	return 0
+}
+
+// firstChild returns the PID of the first child of the process `pid`, or
+// zero when it has none. The children are listed per thread since a child
+// belongs to the thread which forked it.
+func firstChild(pid int) int {
+	tasks, err := filepath.Glob(filepath.Join("/proc", strconv.Itoa(pid), "task", "*", "children"))
+	if err != nil {
+		return 0
+	}
+
+	for _, task := range tasks {
+		data, err := os.ReadFile(task)
+		if err != nil {
+			continue
+		}
+
+		if children := strings.Fields(string(data)); len(children) > 0 {
+			child, _ := strconv.Atoi(children[0])
+			return child
+		}
+	}
+
+	return 0
+}
+
+// running reports whether the helper of the process is still running.
+// Zombie processes and processes which reused the PID of the helper are
+// not considered running.
+func running(rec record) bool {
+	state, started, err := procStat(rec.PID)
+	if err != nil || state == 'Z' {
+		return false
+	}
+
+	return rec.Started == 0 || started == rec.Started
+}
@@ -0,0 +1,181 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/spec"
+)
+
+func Test_journal(t *testing.T) {
+	j := journal{dir: t.TempDir()}
+
+	rec := record{
+		ID:      "job",
+		Alias:   4,
+		Command: "echo",
+		Args:    []string{"hello"},
+		PID:     os.Getpid(),
+	}
+
+	err := j.write(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries which cannot be decoded are skipped.
+	err = os.WriteFile(j.path("corrupt"), []byte("{"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := j.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 1 || recs[0].ID != rec.ID || recs[0].Alias != rec.Alias {
+		t.Fatalf("unexpected journal entries %+v", recs)
+	}
+
+	err = j.remove(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err = j.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 0 {
+		t.Fatalf("expected no journal entries, got %+v", recs)
+	}
+}
+
+func Test_running(t *testing.T) {
+	_, started, err := procStat(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		rec      record
+		expected bool
+	}{
+		"running":    {record{PID: os.Getpid(), Started: started}, true},
+		"reused-pid": {record{PID: os.Getpid(), Started: started + 1}, false},
+		"missing":    {record{PID: -1}, false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if running(test.rec) != test.expected {
+				t.Fatalf("expected running to be %v", test.expected)
+			}
+		})
+	}
+}
+
+func Test_readResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result")
+
+	if _, ok := readResult(path); ok {
+		t.Fatal("expected no result for a missing result file")
+	}
+
+	results := map[string]spec.Termination{
+		"7":                                 {Code: 7},
+		`{"code":137,"signal":9}`:           {Code: 137, Signal: 9},
+		`{"code":2,"error":"no such file"}`: {Code: 2, Error: "no such file"},
+	}
+
+	for data, expected := range results {
+		err := os.WriteFile(path, []byte(data), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		term, ok := readResult(path)
+		if !ok || term != expected {
+			t.Fatalf("expected %+v for %s, got %+v", expected, data, term)
+		}
+	}
+}
+
+func Test_record_terminate(t *testing.T) {
+	tests := map[string]struct {
+		term     spec.Termination
+		stopped  bool
+		expected Reason
+	}{
+		"exited":     {spec.Termination{Code: 1}, false, ReasonExited},
+		"signaled":   {spec.Termination{Code: 137, Signal: 9}, false, ReasonSignaled},
+		"stopped":    {spec.Termination{Code: 143, Signal: 15}, true, ReasonStopped},
+		"oom_killed": {spec.Termination{Code: 137, Signal: 9, OOMKilled: true}, true, ReasonOOMKilled},
+		"failed":     {spec.Termination{Code: 2, Error: "failed"}, true, ReasonFailed},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			rec := record{Stopped: test.stopped}
+			rec.terminate(test.term, time.Now())
+
+			if !rec.Exited || rec.Reason != test.expected {
+				t.Fatalf("expected reason %s, got %s", test.expected, rec.Reason)
+			}
+		})
+	}
+}
+
+func Test_journal_cgroup(t *testing.T) {
+	// Journals in directories sharing their name get distinct cgroups
+	// which are kept when the journal is loaded again.
+	first := journal{dir: filepath.Join(t.TempDir(), "state")}
+	second := journal{dir: filepath.Join(t.TempDir(), "state")}
+
+	names := map[string]bool{}
+	for _, j := range []journal{first, second} {
+		err := os.MkdirAll(j.dir, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		name, err := j.cgroup()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again, err := j.cgroup()
+		if err != nil || again != name {
+			t.Fatalf("expected the cgroup %q to be kept, got %q, %v", name, again, err)
+		}
+
+		names[name] = true
+	}
+
+	if len(names) != 2 {
+		t.Fatalf("expected distinct cgroups, got %v", names)
+	}
+
+	// Journals holding processes started before the cgroup was persisted
+	// keep the name of their directory.
+	legacy := journal{dir: filepath.Join(t.TempDir(), "legacy")}
+	err := os.MkdirAll(legacy.dir, 0700)
+	if err == nil {
+		err = legacy.write(record{ID: "job"})
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, err := legacy.cgroup()
+	if err != nil || name != "legacy" {
+		t.Fatalf("expected the legacy cgroup, got %q, %v", name, err)
+	}
+}
@@ -0,0 +1,469 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/archive"
+	"go.benjiv.com/sandbox/internal/cgroups"
+	"go.benjiv.com/sandbox/internal/iso"
+	"go.benjiv.com/sandbox/internal/network"
+)
+
+// Limits is the set of resource limits applied to a process. Any limit
+// which is not set falls back to the default profile of the Box.
+type Limits = cgroups.Limits
+
+// IOLimit is a bandwidth limit for a single block device.
+type IOLimit = cgroups.IOLimit
+
+// ErrInvalidLimits is returned by StartWith when the resource limits of the
+// process cannot be applied.
+var ErrInvalidLimits = cgroups.ErrInvalidLimits
+
+// Option configures a process started with Box.StartWith.
+type Option func(*options) error
+
+// options is the per process configuration built from the Option values
+// passed to Box.StartWith.
+type options struct {
+	limits Limits
+	tty    bool
+	rootfs string
+	uidMap []IDMap
+	gidMap []IDMap
+	net    network.Config
+
+	identity string
+	deadline time.Duration
+	output   OutputLimit
+}
+
+// WithLimits overrides the default resource limits of the process. Only
+// the limits which are set replace the defaults.
+func WithLimits(l Limits) Option {
+	return func(o *options) error {
+		o.limits = o.limits.Merge(l)
+		return nil
+	}
+}
+
+// WithTTY allocates a pseudo-terminal for the process so interactive tools
+// such as shells and REPLs can be run. The terminal is used for the stdin,
+// stdout and stderr of the process, so all of its output is captured as
+// Stdout, and it can be resized using Box.Resize.
+func WithTTY() Option {
+	return func(o *options) error {
+		o.tty = true
+		return nil
+	}
+}
+
+// WithIdentity records the identity of the requester of the process, e.g.
+// the client certificate of a server, so it is reported in the Status of
+// the process.
+func WithIdentity(identity string) Option {
+	return func(o *options) error {
+		o.identity = identity
+		return nil
+	}
+}
+
+// ErrInvalidDeadline is returned by StartWith when the deadline is not positive.
+var ErrInvalidDeadline = errors.New("invalid deadline")
+
+// WithDeadline stops the process once it has run for `d`. The process is
+// stopped the same way as by Box.Stop and its Status reports
+// ReasonTimedOut.
+func WithDeadline(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidDeadline, d)
+		}
+
+		o.deadline = d
+		return nil
+	}
+}
+
+// OutputMode selects the output retained once a process reaches its
+// output limit.
+type OutputMode string
+
+const (
+	// OutputTruncate retains the first output of the process and
+	// discards the rest. The output ends with a marker noting that it
+	// was truncated.
+	OutputTruncate OutputMode = "truncate"
+
+	// OutputRing retains the most recent output of the process by
+	// discarding the oldest output.
+	OutputRing OutputMode = "ring"
+)
+
+// OutputLimit bounds the output retained for a process.
+type OutputLimit struct {
+	// Bytes is the size of the output retained, including the framing
+	// of the chunks of output, or zero when the output is not limited.
+	Bytes int64
+
+	// Mode selects the output retained once the limit is reached. The
+	// output is truncated by default.
+	Mode OutputMode
+}
+
+// ErrInvalidOutputLimit is returned by StartWith and New when the output limit
+// is not positive or its mode is unknown.
+var ErrInvalidOutputLimit = errors.New("invalid output limit")
+
+// validate checks the bytes and the mode of the output limit.
+func (l OutputLimit) validate() error {
+	if l.Bytes <= 0 {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidOutputLimit, l.Bytes)
+	}
+
+	switch l.Mode {
+	case "", OutputTruncate, OutputRing:
+		return nil
+	default:
+		return fmt.Errorf("%w: mode %q", ErrInvalidOutputLimit, l.Mode)
+	}
+}
+
+// WithOutputLimit bounds the output retained for the process, replacing
+// the default output limit of the Box (see WithOutputBudget). The Status
+// of the process reports whether output was discarded.
+func WithOutputLimit(l OutputLimit) Option {
+	return func(o *options) error {
+		err := l.validate()
+		if err != nil {
+			return err
+		}
+
+		o.output = l
+		return nil
+	}
+}
+
+// ErrInvalidRootFS is returned by StartWith when the root filesystem is not
+// the name of an image of the image directory of the Box.
+var ErrInvalidRootFS = errors.New("invalid root filesystem")
+
+// WithRootFS isolates the filesystem of the process to the image `name` of
+// the image directory of the Box, see WithImageDir. An image is either a
+// directory or a tarball (optionally gzip compressed) that is extracted
+// for the process. The root filesystem is mounted read-only with a fresh
+// /proc, /dev and writable /tmp, and the filesystem of the host is not
+// accessible to the process.
+//
+// Names are relative to the image directory and cannot leave it, so
+// absolute names and names holding ".." are rejected. Missing /proc, /dev
+// and /tmp directories are created in an image directory.
+func WithRootFS(name string) Option {
+	return func(o *options) error {
+		if !validImage(name) {
+			return fmt.Errorf("%w: %q", ErrInvalidRootFS, name)
+		}
+
+		o.rootfs = name
+		return nil
+	}
+}
+
+// validImage reports whether `name` is a clean relative name which stays
+// within the image directory.
+func validImage(name string) bool {
+	if name == "" || filepath.IsAbs(name) || filepath.Clean(name) != name {
+		return false
+	}
+
+	for _, elem := range strings.Split(name, string(filepath.Separator)) {
+		if elem == "." || elem == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IDMap maps a range of user or group IDs in the user namespace of a
+// process to a range of IDs on the host.
+type IDMap = iso.IDMap
+
+// ErrInvalidIDMap is returned by StartWith when the ID maps of the process
+// cannot be applied.
+var ErrInvalidIDMap = iso.ErrInvalidIDMap
+
+// NOTE: The default ID maps follow the common layout of
+// /etc/subuid and /etc/subgid where the first unprivileged range
+// starts at 100000.
+const (
+	// defaultHostID is the first host ID of the default ID maps.
+	defaultHostID = 100000
+
+	// defaultIDRange is the number of IDs in the default ID maps.
+	defaultIDRange = 65536
+)
+
+// defaultIDMaps returns the ID maps used for processes which are not
+// started with WithIDMaps. The root of the process is mapped to an
+// unprivileged range of the host, or to the user and group of this
+// process when running without root privileges.
+func defaultIDMaps() (uidMap, gidMap []IDMap) {
+	if os.Geteuid() != 0 {
+		return []IDMap{{HostID: os.Geteuid(), Size: 1}},
+			[]IDMap{{HostID: os.Getegid(), Size: 1}}
+	}
+
+	m := []IDMap{{HostID: defaultHostID, Size: defaultIDRange}}
+	return m, m
+}
+
+// WithIDMaps overrides the user and group ID maps of the user namespace
+// the process is started in. The root (ID 0) of the process must be
+// mapped. Without root privileges only the user and group of this
+// process can be mapped.
+func WithIDMaps(uidMap, gidMap []IDMap) Option {
+	return func(o *options) error {
+		err := iso.ValidateIDMaps(uidMap)
+		if err != nil {
+			return err
+		}
+
+		err = iso.ValidateIDMaps(gidMap)
+		if err != nil {
+			return err
+		}
+
+		o.uidMap = uidMap
+		o.gidMap = gidMap
+		return nil
+	}
+}
+
+// Network is the network mode of a process. Every process runs in its own
+// network namespace, the mode selects the interfaces available to it.
+type Network = network.Mode
+
+const (
+	// NetworkNone leaves the process without network access, not even
+	// through its loopback interface.
+	NetworkNone = network.None
+
+	// NetworkLoopback brings up the loopback interface of the process.
+	NetworkLoopback = network.Loopback
+
+	// NetworkBridged attaches the process to a bridge on the host with
+	// a private address from the pool of the Box, see WithBridge. The
+	// traffic of the process is NATed to the network of the host, while
+	// the host and the other processes on the bridge cannot be reached.
+	NetworkBridged = network.Bridged
+)
+
+// ErrInvalidNetwork is returned by Start for unknown network modes.
+var ErrInvalidNetwork = network.ErrInvalidMode
+
+// WithNetwork sets the network mode of the process. Processes use
+// NetworkNone by default.
+func WithNetwork(mode Network) Option {
+	return func(o *options) error {
+		mode, err := network.ParseMode(string(mode))
+		if err != nil {
+			return err
+		}
+
+		o.net.Mode = mode
+		return nil
+	}
+}
+
+// NOTE: The bridge is named after the library and the subnet is picked
+// from the private ranges to avoid the defaults of common container
+// runtimes.
+const (
+	// defaultBridge is the name of the bridge of bridged processes.
+	defaultBridge = "sandbox0"
+
+	// defaultSubnet is the subnet the addresses of bridged processes
+	// are allocated from.
+	defaultSubnet = "172.29.0.0/16"
+)
+
+// BoxOption configures a Box created with New.
+type BoxOption func(*Box) error
+
+// defaultGrace is the time a stopped process is given to exit before its
+// processes are killed.
+const defaultGrace = time.Second * 10
+
+// ErrInvalidGracePeriod is returned by New when the grace period is
+// negative.
+var ErrInvalidGracePeriod = errors.New("invalid grace period")
+
+// WithGracePeriod sets the time a process stopped using Stop, or at its
+// deadline, is given to exit before every process of the process is
+// killed. A grace period of zero kills the processes right away. The
+// default grace period is 10 seconds.
+func WithGracePeriod(d time.Duration) BoxOption {
+	return func(b *Box) error {
+		if d < 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidGracePeriod, d)
+		}
+
+		b.grace = d
+		return nil
+	}
+}
+
+// ErrOutputBudget is returned by Start when the output limit of the process
+// exceeds what is left of the output budget of the Box.
+var ErrOutputBudget = errors.New("output budget exhausted")
+
+// WithOutputBudget bounds the output retained for all of the processes of
+// the Box to `total` bytes. Every process reserves its output limit from
+// the budget when it is started until it is released, and processes
+// started without an output limit are limited to `job`. Starting a
+// process fails with ErrOutputBudget when its limit exceeds what is left
+// of the budget. A `total` of zero only limits the output of the processes
+// started without an output limit.
+func WithOutputBudget(total int64, job OutputLimit) BoxOption {
+	return func(b *Box) error {
+		err := job.validate()
+		if err != nil {
+			return err
+		}
+
+		if total < 0 || (total > 0 && total < job.Bytes) {
+			return fmt.Errorf("%w: budget of %d bytes", ErrInvalidOutputLimit, total)
+		}
+
+		b.outputBudget = total
+		b.output = job
+		return nil
+	}
+}
+
+// ErrInvalidRetention is returned by New when the retention of the archive
+// is negative.
+var ErrInvalidRetention = errors.New("invalid archive retention")
+
+// Retention bounds the bundles kept in the archive of a Box, see
+// WithArchive. Zero values do not bound the archive.
+type Retention struct {
+	// Age is the time a bundle is kept after its process is released.
+	Age time.Duration
+
+	// Bytes bounds the size of the bundles of the archive. The oldest
+	// bundles are removed first.
+	Bytes int64
+}
+
+// WithArchive archives the output and the final status of every process
+// which exited to the directory `dir` once the process is released. The
+// bundles of the archive are read using Archived, and are removed once
+// they are no longer within the `retention` of the archive.
+func WithArchive(dir string, retention Retention) BoxOption {
+	return func(b *Box) error {
+		if dir == "" {
+			return errors.New("archive directory is empty")
+		}
+
+		if retention.Age < 0 || retention.Bytes < 0 {
+			return fmt.Errorf("%w: %+v", ErrInvalidRetention, retention)
+		}
+
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return err
+		}
+
+		b.archiveDir = dir
+		b.retention = retention
+
+		return archive.Prune(dir, retention.Age, retention.Bytes)
+	}
+}
+
+// WithStateDir uses `dir` as the directory of the Box instead of a new
+// temp directory. The journal and the output of the processes are kept
+// in the directory so a Box created with the same directory, e.g. after
+// a restart, re-adopts the processes of the previous Box. A directory
+// must not be used by more than one Box at a time, so New locks the
+// directory until the context of the Box is done or the Box is cleaned
+// up, and fails with ErrStateDirLocked while another Box holds the lock.
+func WithStateDir(dir string) BoxOption {
+	return func(b *Box) error {
+		if dir == "" {
+			return errors.New("state directory is empty")
+		}
+
+		b.tempDir = dir
+		return nil
+	}
+}
+
+// ErrStateDirLocked is returned by New when the directory of the Box is
+// used by another Box, possibly of another process.
+var ErrStateDirLocked = errors.New("state directory is locked")
+
+// ErrInvalidImageDir is returned by New when the image directory is not a
+// directory which only the user of the sandbox may write to.
+var ErrInvalidImageDir = errors.New("invalid image directory")
+
+// WithImageDir sets the directory `dir` holding the images processes may
+// use as their root filesystem, see WithRootFS. The directory must be
+// owned by the user of the sandbox, root unless the sandbox is rootless,
+// and must not be writable by other users since the images are trusted.
+// Processes cannot use a root filesystem without an image directory.
+func WithImageDir(dir string) BoxOption {
+	return func(b *Box) error {
+		abs, err := filepath.Abs(dir)
+		if err == nil {
+			abs, err = filepath.EvalSymlinks(abs)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidImageDir, err)
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidImageDir, err)
+		}
+
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !info.IsDir() || !ok || int(st.Uid) != os.Geteuid() || info.Mode().Perm()&0022 != 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidImageDir, dir)
+		}
+
+		b.imageDir = abs
+		return nil
+	}
+}
+
+// WithBridge overrides the bridge on the host that processes started with
+// NetworkBridged are attached to, and the IPv4 subnet in CIDR notation
+// their addresses are allocated from. The first address of the subnet is
+// assigned to the bridge. The bridge is created when the first bridged
+// process is started unless it exists.
+func WithBridge(name, subnet string) BoxOption {
+	return func(b *Box) error {
+		if name == "" || len(name) >= syscall.IFNAMSIZ {
+			return fmt.Errorf("invalid bridge name %q", name)
+		}
+
+		pool, err := network.NewPool(subnet)
+		if err != nil {
+			return err
+		}
+
+		b.bridge = name
+		b.pool = pool
+		return nil
+	}
+}
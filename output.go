@@ -0,0 +1,394 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.benjiv.com/sandbox/internal/capture"
+	"go.benjiv.com/sandbox/internal/spec"
+)
+
+// Stream selects the output streams of a process.
+type Stream uint8
+
+const (
+	// Stdout selects the standard output of the process.
+	Stdout = Stream(capture.Stdout)
+
+	// Stderr selects the standard error of the process.
+	Stderr = Stream(capture.Stderr)
+
+	// Combined selects both streams in the order they were captured.
+	Combined = Stdout | Stderr
+)
+
+// String returns the name of the stream: "stdout", "stderr" or "combined".
+func (s Stream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	case Combined:
+		return "combined"
+	default:
+		return fmt.Sprintf("stream(%d)", uint8(s))
+	}
+}
+
+// TruncatedMarker ends the output of a process which was truncated at its
+// output limit, see OutputTruncate.
+const TruncatedMarker = capture.TruncatedMarker
+
+var (
+	// ErrInvalidOffset is returned by Output for negative offsets and
+	// offsets combined with a tail.
+	ErrInvalidOffset = errors.New("invalid output offset")
+
+	// ErrInvalidTail is returned by Output for a negative number of
+	// lines.
+	ErrInvalidTail = errors.New("invalid output tail")
+)
+
+// Chunk is a piece of output tagged with the stream it was captured from
+// and the time it was captured at. The time is zero for output captured
+// before output was timestamped.
+//
+// Offset is the offset of the chunk in the output and NextOffset the
+// offset of the output following the chunk. Reading the output from
+// NextOffset, see WithOffset, resumes exactly after the chunk. Offsets
+// are positions in the captured output rather than counts of the bytes
+// of the selected streams.
+type Chunk struct {
+	Stream     Stream
+	Data       []byte
+	Time       time.Time
+	Offset     int64
+	NextOffset int64
+}
+
+// chunkJSON is the JSON encoding of a Chunk.
+type chunkJSON struct {
+	Time       *time.Time `json:"time,omitempty"`
+	Stream     string     `json:"stream"`
+	Offset     int64      `json:"offset"`
+	NextOffset int64      `json:"next_offset"`
+	Data       string     `json:"data"`
+}
+
+// MarshalJSON encodes the chunk as a JSON object with the capture time,
+// the name of the stream, the offsets and the data as a string.
+func (c Chunk) MarshalJSON() ([]byte, error) {
+	out := chunkJSON{
+		Stream:     c.Stream.String(),
+		Offset:     c.Offset,
+		NextOffset: c.NextOffset,
+		Data:       string(c.Data),
+	}
+
+	if !c.Time.IsZero() {
+		out.Time = &c.Time
+	}
+
+	return json.Marshal(out)
+}
+
+// OutputOption configures the output read by Box.Output.
+type OutputOption func(*outputOptions) error
+
+// outputOptions are the options of an OutputReader. A tail of -1 reads
+// the output from the offset.
+type outputOptions struct {
+	offset    int64
+	tail      int
+	follow    bool
+	jsonLines bool
+}
+
+// WithOffset reads the output from `offset`, which is either zero or the
+// Offset or NextOffset of a chunk. Output discarded by an output ring is
+// skipped, see OutputRing.
+func WithOffset(offset int64) OutputOption {
+	return func(o *outputOptions) error {
+		if offset < 0 {
+			return fmt.Errorf("%w: %d", ErrInvalidOffset, offset)
+		}
+
+		o.offset = offset
+		return nil
+	}
+}
+
+// WithTail reads the output from the start of the last `lines` lines of
+// the selected streams which were captured when the output is opened.
+// Zero lines skips the output captured so far.
+func WithTail(lines int) OutputOption {
+	return func(o *outputOptions) error {
+		if lines < 0 {
+			return fmt.Errorf("%w: %d lines", ErrInvalidTail, lines)
+		}
+
+		o.tail = lines
+		return nil
+	}
+}
+
+// WithJSONLines reads the output as structured records using Read. Every
+// chunk of the output is read as a line holding the JSON encoding of the
+// chunk, see Chunk.MarshalJSON, instead of its raw data.
+func WithJSONLines() OutputOption {
+	return func(o *outputOptions) error {
+		o.jsonLines = true
+		return nil
+	}
+}
+
+// WithFollow sets whether the output is followed until the process exits,
+// which is the default. Without following, the end of the output captured
+// so far ends the output.
+func WithFollow(follow bool) OutputOption {
+	return func(o *outputOptions) error {
+		o.follow = follow
+		return nil
+	}
+}
+
+// outputNotifier is implemented by the output files which notify their
+// readers of new output, see capture.Notifier. The changed channel is
+// closed once the output is written to after the last read, and the
+// finished channel delivers once the process finished.
+type outputNotifier interface {
+	notified() (changed <-chan struct{}, finished <-chan spec.Termination)
+}
+
+// OutputReader reads the captured output of a process. The output can
+// either be read as raw bytes of the selected streams, or JSON Lines, see
+// WithJSONLines, using Read or as tagged chunks using Next. The two methods
+// should not be mixed.
+type OutputReader struct {
+	rc        io.ReadCloser
+	records   *capture.Reader
+	streams   Stream
+	follow    bool
+	jsonLines bool
+	pending   []byte
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// skip is the number of bytes of the first chunk which are
+	// skipped to start the output at a line of a tail.
+	skip int
+}
+
+// newOutputReader wraps the output file reader `rc` and filters the
+// decoded records down to the selected `streams`.
+func newOutputReader(rc io.ReadCloser, streams Stream) *OutputReader {
+	return &OutputReader{
+		rc:      rc,
+		records: capture.NewReader(rc),
+		streams: streams,
+		follow:  true,
+		closed:  make(chan struct{}),
+	}
+}
+
+// apply positions the reader as configured by `opts`.
+func (o *OutputReader) apply(opts ...OutputOption) error {
+	cfg := outputOptions{tail: -1, follow: true}
+	for _, opt := range opts {
+		err := opt(&cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.offset > 0 && cfg.tail >= 0 {
+		return fmt.Errorf("%w: an offset cannot be combined with a tail", ErrInvalidOffset)
+	}
+
+	o.follow = cfg.follow
+	o.jsonLines = cfg.jsonLines
+
+	if cfg.offset > 0 {
+		return o.records.SeekRecord(cfg.offset)
+	}
+
+	if cfg.tail >= 0 {
+		return o.tail(cfg.tail)
+	}
+
+	return nil
+}
+
+// lineStart is the start of a line in the output: the offset of the record
+// and the index of the line in the data of the record.
+type lineStart struct {
+	offset int64
+	index  int
+}
+
+// tail reads the output captured so far and positions the reader at the
+// start of the last `lines` lines of the selected streams.
+func (o *OutputReader) tail(lines int) error {
+	var starts []lineStart
+	atStart := true
+
+	for {
+		rec, err := o.records.Next()
+		if err == io.EOF || (err == nil && len(rec.Data) == 0) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if Stream(rec.Stream)&o.streams == 0 {
+			continue
+		}
+
+		for i := 0; i < len(rec.Data); {
+			if atStart && lines > 0 {
+				// Only the starts of the last lines are kept.
+				if len(starts) == lines {
+					starts = starts[1:]
+				}
+
+				starts = append(starts, lineStart{rec.Offset, i})
+			}
+
+			n := bytes.IndexByte(rec.Data[i:], '\n')
+			atStart = n >= 0
+			if n < 0 {
+				break
+			}
+
+			i += n + 1
+		}
+	}
+
+	// The output is read up to its end when there are no lines.
+	if len(starts) == 0 {
+		return nil
+	}
+
+	o.skip = starts[0].index
+	return o.records.SeekRecord(starts[0].offset)
+}
+
+// Next returns the next chunk of output from the selected streams. While
+// the process is running and no new output is available an empty chunk
+// is returned with a nil error, after which Wait waits for new output,
+// or io.EOF when the output is not followed. Once the process has exited
+// and all of the output is read io.EOF is returned.
+func (o *OutputReader) Next() (Chunk, error) {
+	for {
+		rec, err := o.records.Next()
+		if err == nil && len(rec.Data) == 0 && !o.follow {
+			err = io.EOF
+		}
+
+		if err != nil || len(rec.Data) == 0 {
+			return Chunk{}, err
+		}
+
+		s := Stream(rec.Stream)
+		if s&o.streams == 0 {
+			continue
+		}
+
+		data := rec.Data
+		if o.skip > 0 {
+			data = data[o.skip:]
+			o.skip = 0
+		}
+
+		return Chunk{
+			Stream:     s,
+			Data:       data,
+			Time:       rec.Time,
+			Offset:     rec.Offset,
+			NextOffset: rec.End(),
+		}, nil
+	}
+}
+
+// Wait blocks until new output may be available after Next returned an
+// empty chunk. Wait returns once the output is written to, the process
+// exits or the reader is closed, or with the error of `ctx` once it is
+// done. Readers following the output wait instead of polling Next, so
+// any number of readers can follow the output of a process.
+func (o *OutputReader) Wait(ctx context.Context) error {
+	var changed <-chan struct{}
+	var finished <-chan spec.Termination
+	var poll <-chan time.Time
+
+	if n, ok := o.rc.(outputNotifier); ok {
+		changed, finished = n.notified()
+	} else {
+		// Output which does not notify its readers is polled.
+		timer := time.NewTimer(pollInterval)
+		defer timer.Stop()
+
+		poll = timer.C
+	}
+
+	select {
+	case <-changed:
+	case <-finished:
+	case <-poll:
+	case <-o.closed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Read reads the raw output of the selected streams. While the output of
+// a running process is followed, Read waits for new output, see Wait.
+func (o *OutputReader) Read(p []byte) (int, error) {
+	for len(o.pending) == 0 {
+		c, err := o.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		if len(c.Data) == 0 {
+			err = o.Wait(context.Background())
+			if err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		o.pending = c.Data
+		if o.jsonLines {
+			line, err := json.Marshal(c)
+			if err != nil {
+				return 0, err
+			}
+
+			o.pending = append(line, '\n')
+		}
+	}
+
+	n := copy(p, o.pending)
+	o.pending = o.pending[n:]
+
+	return n, nil
+}
+
+// Close closes the underlying output file and wakes a waiting reader.
+func (o *OutputReader) Close() error {
+	o.closeOnce.Do(func() { close(o.closed) })
+	return o.rc.Close()
+}
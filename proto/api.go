@@ -6,7 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"go.benjiv.com/sandbox"
 	"go.benjiv.com/sandbox/internal/tls"
@@ -25,9 +28,43 @@ type logger interface {
 // cmdSrv is a server implementation of the CommandServiceServer interface.
 type cmdSrv struct {
 	UnimplementedCommandServiceServer
-	box   *sandbox.Box
-	roles tls.OrgRoles
-	log   logger
+	box    *sandbox.Box
+	roles  tls.OrgRoles
+	log    logger
+	policy Policy
+}
+
+// Policy selects which clients may act on a process other than the client
+// which started it, the owner of the process.
+type Policy string
+
+const (
+	// PolicyOwner only allows the owner of a process and clients with
+	// the privileged role to act on it.
+	PolicyOwner Policy = "owner"
+
+	// PolicyCommand allows every client which is allowed to run the
+	// command of a process to act on it.
+	PolicyCommand Policy = "command"
+)
+
+// ErrInvalidPolicy is returned by WithPolicy for an unknown policy.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// ServerOption configures the server created by NewServer.
+type ServerOption func(*cmdSrv) error
+
+// WithPolicy sets the policy which selects the clients allowed to act on a
+// process, see Policy. The default policy is PolicyOwner.
+func WithPolicy(p Policy) ServerOption {
+	return func(c *cmdSrv) error {
+		if p != PolicyOwner && p != PolicyCommand {
+			return fmt.Errorf("%w: %s", ErrInvalidPolicy, p)
+		}
+
+		c.policy = p
+		return nil
+	}
 }
 
 // ErrAuthenticationFailure is the default error returned by the server
@@ -57,7 +94,74 @@ func (c *cmdSrv) Start(ctx context.Context, in *Command) (*Process, error) {
 		return nil, ErrAuthenticationFailure
 	}
 
-	id, err := c.box.Start(in.Command, in.Args...)
+	network := sandboxNetwork(in.Network)
+	err = c.networkCheck(network, cert)
+	if err != nil {
+		c.log.Errorf(
+			"cert [%d] failed role check for network %s: %s",
+			int(cert.SerialNumber.Int64()),
+			network,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	err = c.rootfsCheck(in.Rootfs, cert)
+	if err != nil {
+		c.log.Errorf(
+			"cert [%d] failed role check for rootfs %s: %s",
+			int(cert.SerialNumber.Int64()),
+			in.Rootfs,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	deadline, err := c.deadlineCheck(time.Duration(in.Deadline)*time.Microsecond, cert)
+	if err != nil {
+		c.log.Errorf(
+			"cert [%d] failed role check for deadline %s: %s",
+			int(cert.SerialNumber.Int64()),
+			time.Duration(in.Deadline)*time.Microsecond,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	opts := []sandbox.Option{
+		sandbox.WithLimits(sandboxLimits(in.Limits)),
+		sandbox.WithNetwork(network),
+		sandbox.WithIdentity(identity(cert)),
+	}
+
+	if deadline != 0 {
+		opts = append(opts, sandbox.WithDeadline(deadline))
+	}
+
+	if in.Tty {
+		opts = append(opts, sandbox.WithTTY())
+	}
+
+	if in.Rootfs != "" {
+		opts = append(opts, sandbox.WithRootFS(in.Rootfs))
+	}
+
+	if in.OutputLimit.GetBytes() > 0 {
+		opts = append(opts, sandbox.WithOutputLimit(sandboxOutputLimit(in.OutputLimit)))
+	}
+
+	id, err := c.box.StartWith(in.Command, in.Args, opts...)
+	if errors.Is(err, sandbox.ErrOutputBudget) {
+		// The output of finished processes holds the budget
+		// until they are released, so the client is told to
+		// retry later rather than that the start failed.
+		c.log.Errorf("failed to start process: %s", err)
+		return nil, fmt.Errorf(
+			"%w, retry once processes are released or start the command with a smaller output limit",
+			err,
+		)
+	}
+
 	if err != nil {
 		c.log.Errorf("failed to start process: %s", err)
 		return nil, err
@@ -68,47 +172,181 @@ func (c *cmdSrv) Start(ctx context.Context, in *Command) (*Process, error) {
 		args = fmt.Sprintf(" with args [%s]", strings.Join(in.Args, " "))
 	}
 
+	alias, err := c.box.Alias(id)
+	if err != nil {
+		c.log.Errorf("failed to get alias of process %s: %s", id, err)
+		return nil, err
+	}
+
 	c.log.Printf(
-		"starting command [%s]%s for cert [%d]; id: %d",
+		"starting command [%s]%s for cert [%d]; id: %s",
 		in.Command,
 		args,
 		int(cert.SerialNumber.Int64()),
 		id,
 	)
 	return &Process{
-		Id: int64(id),
+		Id:   alias,
+		Uuid: id,
 	}, nil
 }
 
-// Output streams the stdout and stderr of the process to the client.
-func (c *cmdSrv) Output(in *Process, svc CommandService_OutputServer) error {
-	id, err := c.roleCheckByID(svc.Context(), in.Id)
+// identifier is implemented by the messages which reference a process.
+type identifier interface {
+	GetId() int64
+	GetUuid() string
+}
+
+// processID returns the ID of the process referenced by the message. The
+// numeric alias is used for clients which do not set the uuid.
+func processID(in identifier) string {
+	if in.GetUuid() != "" {
+		return in.GetUuid()
+	}
+
+	return strconv.FormatInt(in.GetId(), 10)
+}
+
+// Output streams the selected output streams of the process to the client.
+func (c *cmdSrv) Output(in *OutputRequest, svc CommandService_OutputServer) error {
+	if in.Archived {
+		return c.archivedOutput(in, svc)
+	}
+
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(svc.Context(), pid)
 	if err != nil {
 		// TODO: These logs should be higher than "ERROR" and should
 		// trigger notifications to the security team as they are
 		// potentially a security issue.
 		c.log.Errorf(
-			"cert [%d] failed role check for process %d: %s",
+			"cert [%d] failed role check for process %s: %s",
 			id,
-			in.Id,
+			pid,
 			err,
 		)
 		return ErrAuthenticationFailure
 	}
 
-	rc, err := c.box.Output(int(in.Id))
+	streams, opts := outputOptions(in)
+	out, err := c.box.Output(pid, streams, opts...)
 	if err != nil {
 		c.log.Errorf("failed to get output: %s", err)
 		return err
 	}
-	defer rc.Close()
+	defer out.Close()
 
 	c.log.Printf(
-		"streaming output of process %d for cert [%d]",
-		in.Id,
+		"streaming %s output of process %s for cert [%d]",
+		in.Stream,
+		pid,
 		id,
 	)
-	buff := make([]byte, 1024)
+
+	return c.streamOutput(pid, in.Stream, in.Records, out, svc)
+}
+
+// archivedOutput streams the selected output streams of a released process
+// from the archive of the server to the client. The client must be allowed
+// to act on the process recorded in the archive.
+func (c *cmdSrv) archivedOutput(in *OutputRequest, svc CommandService_OutputServer) error {
+	pid := processID(in)
+
+	// The access is checked against the archived status before the
+	// output of the process is decompressed.
+	status, err := c.box.ArchivedStatus(pid)
+	if errors.Is(err, sandbox.ErrProcessNotFound) {
+		return errors.New("process not found")
+	}
+
+	if err != nil {
+		c.log.Errorf("failed to get archived status: %s", err)
+		return err
+	}
+
+	cert, err := c.certFromContext(svc.Context())
+	if err == nil {
+		err = c.accessCheck(status, cert)
+	}
+
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"failed role check for archived process %s: %s",
+			pid,
+			err,
+		)
+		return ErrAuthenticationFailure
+	}
+
+	streams, opts := outputOptions(in)
+	_, out, err := c.box.Archived(pid, streams, opts...)
+	if errors.Is(err, sandbox.ErrProcessNotFound) {
+		return errors.New("process not found")
+	}
+
+	if err != nil {
+		c.log.Errorf("failed to get archived output: %s", err)
+		return err
+	}
+	defer out.Close()
+
+	c.log.Printf(
+		"streaming archived %s output of process %s for cert [%d]",
+		in.Stream,
+		pid,
+		cert.SerialNumber.Int64(),
+	)
+
+	return c.streamOutput(pid, in.Stream, in.Records, out, svc)
+}
+
+// outputOptions returns the streams and the options of the output selected
+// by the request.
+func outputOptions(in *OutputRequest) (sandbox.Stream, []sandbox.OutputOption) {
+	streams := sandbox.Combined
+	switch in.Stream {
+	case Stream_STDOUT:
+		streams = sandbox.Stdout
+	case Stream_STDERR:
+		streams = sandbox.Stderr
+	case Stream_COMBINED, Stream_INTERLEAVED:
+		// Both streams are read for the combined and interleaved modes.
+	}
+
+	opts := []sandbox.OutputOption{sandbox.WithFollow(!in.NoFollow)}
+	if in.Offset != 0 {
+		opts = append(opts, sandbox.WithOffset(in.Offset))
+	}
+
+	if in.TailLines != 0 {
+		opts = append(opts, sandbox.WithTail(int(in.TailLines)))
+	}
+
+	return streams, opts
+}
+
+// outputStream is implemented by the server streams which send the output
+// of a process to the client.
+type outputStream interface {
+	Context() context.Context
+	Send(*CommandOutput) error
+}
+
+// streamOutput sends the output read from `out` to the client until the
+// process exits or the client disconnects. In INTERLEAVED mode, or when
+// `records` are requested, each chunk is tagged with the stream it came
+// from. Records are also tagged with their capture time.
+func (c *cmdSrv) streamOutput(
+	id string,
+	mode Stream,
+	records bool,
+	out *sandbox.OutputReader,
+	svc outputStream,
+) error {
 	for {
 		// Adhere to the context.
 		select {
@@ -117,21 +355,49 @@ func (c *cmdSrv) Output(in *Process, svc CommandService_OutputServer) error {
 		default:
 		}
 
-		n, err := rc.Read(buff)
+		chunk, err := out.Next()
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			c.log.Errorf("error reading output for process %d: %s", in.Id, err)
+			c.log.Errorf("error reading output for process %s: %s", id, err)
 			return err
 		}
 
-		err = svc.Send(&CommandOutput{
-			Data: buff[:n],
-		})
+		// No new output is available yet, so the stream waits
+		// for the output to be written to.
+		if len(chunk.Data) == 0 {
+			err = out.Wait(svc.Context())
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		stream := mode
+		if stream == Stream_INTERLEAVED || records {
+			stream = Stream_STDOUT
+			if chunk.Stream == sandbox.Stderr {
+				stream = Stream_STDERR
+			}
+		}
+
+		msg := &CommandOutput{
+			Data:       chunk.Data,
+			Stream:     stream,
+			Offset:     chunk.Offset,
+			NextOffset: chunk.NextOffset,
+		}
+
+		if records && !chunk.Time.IsZero() {
+			msg.Time = chunk.Time.UnixMicro()
+		}
+
+		err = svc.Send(msg)
 		if err != nil {
-			c.log.Errorf("error sending output for process %d: %s", in.Id, err)
+			c.log.Errorf("error sending output for process %s: %s", id, err)
 			return err
 		}
 	}
@@ -139,34 +405,289 @@ func (c *cmdSrv) Output(in *Process, svc CommandService_OutputServer) error {
 	return nil
 }
 
-func (c *cmdSrv) Stop(ctx context.Context, in *Process) (*Status, error) {
-	id, err := c.roleCheckByID(ctx, in.Id)
+// Attach connects the client to the terminal of the process. Keystrokes and
+// resize events from the client are relayed to the process while the output
+// of the process is streamed back until the process exits. Closing the send
+// side of the stream detaches the client without closing the stdin of the
+// process.
+func (c *cmdSrv) Attach(svc CommandService_AttachServer) error {
+	in, err := svc.Recv()
+	if err != nil {
+		return err
+	}
+
+	// The process ID is only read from the first message.
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(svc.Context(), pid)
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"cert [%d] failed role check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return ErrAuthenticationFailure
+	}
+
+	wc, err := c.box.Input(pid)
+	if err != nil {
+		c.log.Errorf("failed to get input: %s", err)
+		return err
+	}
+
+	out, err := c.box.Output(pid, sandbox.Combined)
+	if err != nil {
+		c.log.Errorf("failed to get output: %s", err)
+		return err
+	}
+	defer out.Close()
+
+	c.log.Printf("attaching to process %s for cert [%d]", pid, id)
+
+	// Relay the keystrokes and resize events of the client.
+	go func(in *AttachRequest) {
+		for {
+			if in.Size != nil {
+				err := c.box.Resize(
+					pid,
+					uint16(in.Size.Rows),
+					uint16(in.Size.Cols),
+				)
+				if err != nil {
+					c.log.Errorf("error resizing process %s: %s", pid, err)
+				}
+			}
+
+			if len(in.Data) > 0 {
+				_, err := wc.Write(in.Data)
+				if err != nil {
+					c.log.Errorf("error writing input for process %s: %s", pid, err)
+					return
+				}
+			}
+
+			var err error
+			in, err = svc.Recv()
+			if err != nil {
+				return
+			}
+		}
+	}(in)
+
+	return c.streamOutput(pid, Stream_INTERLEAVED, false, out, svc)
+}
+
+// Input streams data from the client to the stdin of the process. The stdin
+// of the process is closed once the client closes the stream.
+func (c *cmdSrv) Input(svc CommandService_InputServer) error {
+	in, err := svc.Recv()
+	if err != nil {
+		return err
+	}
+
+	// The process ID is only read from the first message.
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(svc.Context(), pid)
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"cert [%d] failed role check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return ErrAuthenticationFailure
+	}
+
+	wc, err := c.box.Input(pid)
+	if err != nil {
+		c.log.Errorf("failed to get input: %s", err)
+		return err
+	}
+
+	c.log.Printf(
+		"streaming input to process %s for cert [%d]",
+		pid,
+		id,
+	)
+	for {
+		_, err = wc.Write(in.Data)
+		if err != nil {
+			c.log.Errorf("error writing input for process %s: %s", pid, err)
+			return err
+		}
+
+		in, err = svc.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			c.log.Errorf("error receiving input for process %s: %s", pid, err)
+			return err
+		}
+	}
+
+	err = wc.Close()
+	if err != nil {
+		c.log.Errorf("error closing input for process %s: %s", pid, err)
+		return err
+	}
+
+	status, err := c.Stat(svc.Context(), &Process{Uuid: pid})
+	if err != nil {
+		return err
+	}
+
+	return svc.SendAndClose(status)
+}
+
+func (c *cmdSrv) Stop(ctx context.Context, in *StopRequest) (*Status, error) {
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(ctx, pid)
 	if err != nil {
 		// TODO: These logs should be higher than "ERROR" and should
 		// trigger notifications to the security team as they are
 		// potentially a security issue.
 		c.log.Errorf(
-			"cert [%d] failed role check for process %d: %s",
+			"cert [%d] failed role check for process %s: %s",
 			id,
-			in.Id,
+			pid,
 			err,
 		)
 		return nil, ErrAuthenticationFailure
 	}
 
-	c.log.Printf("stopping process %d for certificate %d", in.Id, id)
-	err = c.box.Stop(int(in.Id))
+	c.log.Printf("stopping process %s for certificate %d", pid, id)
+	if in.GracePeriod > 0 {
+		err = c.box.StopWithGrace(pid, time.Duration(in.GracePeriod)*time.Microsecond)
+	} else {
+		err = c.box.Stop(pid)
+	}
+
 	if err != nil {
 		c.log.Errorf("failed to stop process: %s", err)
 		return nil, err
 	}
 
+	return c.Stat(ctx, &Process{Id: in.Id, Uuid: in.Uuid})
+}
+
+// Pause freezes the command until it is resumed.
+func (c *cmdSrv) Pause(ctx context.Context, in *Process) (*Status, error) {
+	return c.setPaused(ctx, in, true)
+}
+
+// Resume continues the command paused by Pause.
+func (c *cmdSrv) Resume(ctx context.Context, in *Process) (*Status, error) {
+	return c.setPaused(ctx, in, false)
+}
+
+// setPaused pauses or resumes the command after checking the role of the
+// client.
+func (c *cmdSrv) setPaused(ctx context.Context, in *Process, paused bool) (*Status, error) {
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(ctx, pid)
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"cert [%d] failed role check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	if paused {
+		c.log.Printf("pausing process %s for certificate %d", pid, id)
+		err = c.box.Pause(pid)
+	} else {
+		c.log.Printf("resuming process %s for certificate %d", pid, id)
+		err = c.box.Resume(pid)
+	}
+
+	if err != nil {
+		c.log.Errorf("failed to pause or resume process: %s", err)
+		return nil, err
+	}
+
 	return c.Stat(ctx, in)
 }
 
+// Signal sends the signal to the command or its process group after
+// checking the role of the client.
+func (c *cmdSrv) Signal(ctx context.Context, in *SignalRequest) (*Status, error) {
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(ctx, pid)
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"cert [%d] failed role check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	cert, err := c.certFromContext(ctx)
+	if err != nil {
+		return nil, ErrAuthenticationFailure
+	}
+
+	sig := syscall.Signal(in.Signal)
+	err = c.signalCheck(sig, cert)
+	if err != nil {
+		c.log.Errorf(
+			"cert [%d] failed signal check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return nil, ErrAuthenticationFailure
+	}
+
+	c.log.Printf(
+		"sending %s to process %s for certificate %d",
+		sandbox.SignalName(sig),
+		pid,
+		id,
+	)
+
+	if in.Group {
+		err = c.box.SignalGroup(pid, sig)
+	} else {
+		err = c.box.Signal(pid, sig)
+	}
+
+	if err != nil {
+		c.log.Errorf("failed to signal process: %s", err)
+		return nil, err
+	}
+
+	return c.Stat(ctx, &Process{Id: in.Id, Uuid: in.Uuid})
+}
+
 // Stat returns the status of the command.
 func (c *cmdSrv) Stat(ctx context.Context, in *Process) (*Status, error) {
-	status, err := c.box.Stat(int(in.Id))
+	pid := processID(in)
+
+	status, err := c.box.Stat(pid)
 	if err != nil {
 		return nil, errors.New("process not found")
 	}
@@ -176,29 +697,254 @@ func (c *cmdSrv) Stat(ctx context.Context, in *Process) (*Status, error) {
 		return nil, ErrAuthenticationFailure
 	}
 
-	err = c.roleCheck(status.Command, cert)
+	err = c.accessCheck(status, cert)
 	if err != nil {
 		// TODO: These logs should be higher than "ERROR" and should
 		// trigger notifications to the security team as they are
 		// potentially a security issue.
 		c.log.Errorf(
-			"cert [%d] failed role check for process %d: %s",
+			"cert [%d] failed role check for process %s: %s",
 			int(cert.SerialNumber.Int64()),
-			in.Id,
+			pid,
 			err,
 		)
 		return nil, ErrAuthenticationFailure
 	}
 
 	c.log.Printf(
-		"stating process %d for certificate %d",
-		in.Id,
+		"stating process %s for certificate %d",
+		pid,
 		int(cert.SerialNumber.Int64()),
 	)
-	return &Status{
-		Exited:   status.Exited,
-		Exitcode: int32(status.Code),
-	}, nil
+
+	// The usage is left out when it is not accounted, e.g. for
+	// a rootless server.
+	var usage *Usage
+	if u, err := c.box.Usage(pid); err == nil {
+		usage = &Usage{
+			Memory:           u.Memory,
+			MemoryPeak:       u.MemoryPeak,
+			CpuTime:          u.CPUTime.Microseconds(),
+			ThrottledPeriods: u.ThrottledPeriods,
+			IoReadBytes:      u.IORead,
+			IoWriteBytes:     u.IOWrite,
+			Pids:             u.Pids,
+		}
+	}
+
+	out := protoStatus(status)
+	out.Usage = usage
+
+	return out, nil
+}
+
+// Watch streams the status transitions of the command until it is released
+// or the client disconnects.
+func (c *cmdSrv) Watch(in *Process, svc CommandService_WatchServer) error {
+	pid := processID(in)
+
+	id, err := c.roleCheckByID(svc.Context(), pid)
+	if err != nil {
+		// TODO: These logs should be higher than "ERROR" and should
+		// trigger notifications to the security team as they are
+		// potentially a security issue.
+		c.log.Errorf(
+			"cert [%d] failed role check for process %s: %s",
+			id,
+			pid,
+			err,
+		)
+		return ErrAuthenticationFailure
+	}
+
+	events, err := c.box.Watch(pid)
+	if err != nil {
+		c.log.Errorf("failed to watch process: %s", err)
+		return err
+	}
+
+	c.log.Printf("watching process %s for cert [%d]", pid, id)
+
+	for {
+		select {
+		case <-svc.Context().Done():
+			return svc.Context().Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			err = svc.Send(&Event{
+				Type:   protoEventType(e.Type),
+				Status: protoStatus(e.Status),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// List returns the processes selected by the request which the client is
+// allowed to act on.
+func (c *cmdSrv) List(ctx context.Context, in *ListRequest) (*ListResponse, error) {
+	cert, err := c.certFromContext(ctx)
+	if err != nil {
+		return nil, ErrAuthenticationFailure
+	}
+
+	summaries, err := c.box.List(sandbox.Filter{
+		State:   sandboxState(in.State),
+		Command: in.Command,
+		Owner:   in.Owner,
+	})
+	if err != nil {
+		c.log.Errorf("failed to list processes: %s", err)
+		return nil, err
+	}
+
+	c.log.Printf(
+		"listing processes for certificate %d",
+		int(cert.SerialNumber.Int64()),
+	)
+
+	out := &ListResponse{}
+	for _, s := range summaries {
+		if c.accessCheck(s.Status, cert) != nil {
+			continue
+		}
+
+		out.Jobs = append(out.Jobs, &Job{
+			Process: &Process{Id: s.Alias, Uuid: s.ID},
+			Status:  protoStatus(s.Status),
+		})
+	}
+
+	return out, nil
+}
+
+// sandboxLimits converts the protobuf limits into the sandbox limits. Limits
+// which are not set are left as zero values so the sandbox default is used.
+func sandboxLimits(l *Limits) sandbox.Limits {
+	var io []sandbox.IOLimit
+	for _, limit := range l.GetIo() {
+		io = append(io, sandbox.IOLimit{
+			Device:   limit.GetDevice(),
+			ReadBPS:  limit.GetReadBps(),
+			WriteBPS: limit.GetWriteBps(),
+		})
+	}
+
+	return sandbox.Limits{
+		Memory:    l.GetMemory(),
+		CPUQuota:  l.GetCpuQuota(),
+		CPUPeriod: l.GetCpuPeriod(),
+		IO:        io,
+		Pids:      l.GetPids(),
+	}
+}
+
+// sandboxNetwork converts the protobuf network mode into the sandbox network
+// mode.
+func sandboxNetwork(n Network) sandbox.Network {
+	switch n {
+	case Network_LOOPBACK:
+		return sandbox.NetworkLoopback
+	case Network_BRIDGED:
+		return sandbox.NetworkBridged
+	default:
+		return sandbox.NetworkNone
+	}
+}
+
+// sandboxState converts the protobuf state into the sandbox state.
+func sandboxState(s ListRequest_State) sandbox.State {
+	switch s {
+	case ListRequest_RUNNING:
+		return sandbox.StateRunning
+	case ListRequest_EXITED:
+		return sandbox.StateExited
+	default:
+		return sandbox.StateAny
+	}
+}
+
+// sandboxOutputLimit converts the protobuf output limit into the sandbox
+// output limit.
+func sandboxOutputLimit(l *OutputLimit) sandbox.OutputLimit {
+	mode := sandbox.OutputTruncate
+	if l.Mode == OutputLimit_RING {
+		mode = sandbox.OutputRing
+	}
+
+	return sandbox.OutputLimit{Bytes: l.Bytes, Mode: mode}
+}
+
+// protoStatus converts the sandbox status into the protobuf status.
+func protoStatus(status sandbox.Status) *Status {
+	out := &Status{
+		Exited:    status.Exited,
+		Exitcode:  int32(status.Code),
+		Reason:    protoReason(status.Reason),
+		Signal:    int32(status.Signal),
+		Error:     status.Error,
+		Command:   status.Command,
+		Args:      status.Args,
+		Identity:  status.Identity,
+		Pid:       int32(status.PID),
+		JobPid:    int32(status.JobPID),
+		Duration:  status.Duration.Microseconds(),
+		Paused:    status.Paused,
+		Truncated: status.Truncated,
+	}
+
+	if !status.Started.IsZero() {
+		out.StartTime = status.Started.UnixMicro()
+	}
+
+	if !status.Finished.IsZero() {
+		out.EndTime = status.Finished.UnixMicro()
+	}
+
+	if !status.Deadline.IsZero() {
+		out.DeadlineTime = status.Deadline.UnixMicro()
+	}
+
+	return out
+}
+
+// protoReason converts the sandbox termination reason into the protobuf
+// termination reason.
+func protoReason(r sandbox.Reason) Reason {
+	switch r {
+	case sandbox.ReasonExited:
+		return Reason_EXITED
+	case sandbox.ReasonSignaled:
+		return Reason_SIGNALED
+	case sandbox.ReasonOOMKilled:
+		return Reason_OOM_KILLED
+	case sandbox.ReasonStopped:
+		return Reason_STOPPED
+	case sandbox.ReasonTimedOut:
+		return Reason_TIMED_OUT
+	case sandbox.ReasonFailed:
+		return Reason_FAILED
+	default:
+		return Reason_RUNNING
+	}
+}
+
+// protoEventType converts the sandbox event type into the protobuf event
+// type.
+func protoEventType(t sandbox.EventType) Event_Type {
+	switch t {
+	case sandbox.EventExited:
+		return Event_EXITED
+	case sandbox.EventReleased:
+		return Event_RELEASED
+	default:
+		return Event_STARTED
+	}
 }
 
 // NewServer creates a new instances of the CmdSrv server which adds the
@@ -209,24 +955,34 @@ func NewServer(
 	log logger,
 	box *sandbox.Box,
 	roles tls.OrgRoles,
+	opts ...ServerOption,
 ) (CommandServiceServer, error) {
 	if log == nil {
 		return nil, errors.New("logger is nil")
 	}
 
-	return &cmdSrv{
-		box:   box,
-		roles: roles,
-		log:   log,
-	}, nil
+	c := &cmdSrv{
+		box:    box,
+		roles:  roles,
+		log:    log,
+		policy: PolicyOwner,
+	}
+
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
 }
 
-// roleCheckByID stat's the process and checks the role of the certificate
-// against the command that is running with that id. If the command for that
-// id is NOT allowed to run by the certificate, an error is returned, otherwise
-// nil.
-func (c *cmdSrv) roleCheckByID(ctx context.Context, id int64) (int, error) {
-	status, err := c.box.Stat(int(id))
+// roleCheckByID stat's the process and checks that the certificate is
+// allowed to act on the process with that id, see accessCheck. If it is NOT
+// allowed, an error is returned, otherwise nil.
+func (c *cmdSrv) roleCheckByID(ctx context.Context, id string) (int, error) {
+	status, err := c.box.Stat(id)
 	if err != nil {
 		return 0, errors.New("process not found")
 	}
@@ -236,7 +992,7 @@ func (c *cmdSrv) roleCheckByID(ctx context.Context, id int64) (int, error) {
 		return 0, ErrAuthenticationFailure
 	}
 
-	err = c.roleCheck(status.Command, cert)
+	err = c.accessCheck(status, cert)
 	if err != nil {
 		return 0, ErrAuthenticationFailure
 	}
@@ -244,6 +1000,53 @@ func (c *cmdSrv) roleCheckByID(ctx context.Context, id int64) (int, error) {
 	return int(cert.SerialNumber.Int64()), nil
 }
 
+// accessCheck handles the evaluation of whether the certificate may act on
+// the process with the given status. The certificate must be allowed to run
+// the command of the process and, under PolicyOwner, must be the owner of
+// the process or have the privileged role.
+func (c *cmdSrv) accessCheck(
+	status sandbox.Status,
+	cert *x509.Certificate,
+) error {
+	err := c.roleCheck(status.Command, cert)
+	if err != nil {
+		return err
+	}
+
+	if c.policy == PolicyCommand || status.Identity == identity(cert) {
+		return nil
+	}
+
+	// Clients with the "*" role or a role allowing any job are
+	// privileged.
+	role := tls.GetRole(
+		c.roles,
+		cert.Subject.Organization,
+		cert.Subject.OrganizationalUnit,
+	)
+
+	if role.Commands["*"] || role.AnyJob {
+		return nil
+	}
+
+	return ErrAuthenticationFailure
+}
+
+// identity returns the identity of the client of the certificate which is
+// recorded with the processes it starts. It is made up of the organizations,
+// the organizational units, the serial number and the issuer of the
+// certificate. The serial number is only unique per issuer, and is kept in
+// full since it may be up to 20 bytes long.
+func identity(cert *x509.Certificate) string {
+	return fmt.Sprintf(
+		"%s/%s/%s@%s",
+		strings.Join(cert.Subject.Organization, ","),
+		strings.Join(cert.Subject.OrganizationalUnit, ","),
+		cert.SerialNumber.String(),
+		cert.Issuer.String(),
+	)
+}
+
 // certFromContext extracts the TLS certificate from the context using the
 // gRPC peer information.
 func (c *cmdSrv) certFromContext(
@@ -296,3 +1099,122 @@ func (c *cmdSrv) roleCheck(
 
 	return nil
 }
+
+// deadlineCheck handles the role evaluation for the given deadline using the
+// metadata from the supplied certificate and the roles defined in the server.
+// The maximum deadline of a client is the largest deadline of its roles and
+// clients without a deadline, or with the "*" role, are not limited. The
+// deadline of the command is returned, which is the maximum deadline when the
+// command has no deadline.
+func (c *cmdSrv) deadlineCheck(
+	deadline time.Duration,
+	cert *x509.Certificate,
+) (time.Duration, error) {
+	role := tls.GetRole(
+		c.roles,
+		cert.Subject.Organization,
+		cert.Subject.OrganizationalUnit,
+	)
+
+	// TODO: The "admin" bypass is the same simple, insecure
+	// implementation as in roleCheck.
+	if _, full := role.Commands["*"]; full {
+		return deadline, nil
+	}
+
+	max := time.Duration(role.Deadline)
+
+	switch {
+	case max == 0:
+		return deadline, nil
+	case deadline == 0:
+		return max, nil
+	case deadline > max:
+		return 0, fmt.Errorf("deadline %s exceeds the maximum of %s", deadline, max)
+	}
+
+	return deadline, nil
+}
+
+// signalCheck handles the role evaluation for the given signal using the
+// metadata from the supplied certificate and the roles defined in the
+// server. Signals must be listed by the signals of the roles, by the name
+// of the signal, e.g. "SIGHUP", or the number of signals without a name.
+func (c *cmdSrv) signalCheck(
+	sig syscall.Signal,
+	cert *x509.Certificate,
+) error {
+	role := tls.GetRole(
+		c.roles,
+		cert.Subject.Organization,
+		cert.Subject.OrganizationalUnit,
+	)
+
+	// TODO: The "admin" bypass is the same simple, insecure
+	// implementation as in roleCheck.
+	if _, full := role.Commands["*"]; !full {
+		if !role.AllowsSignal(sandbox.SignalName(sig)) {
+			return ErrAuthenticationFailure
+		}
+	}
+
+	return nil
+}
+
+// rootfsCheck handles the role evaluation for the given root filesystem using
+// the metadata from the supplied certificate and the roles defined in the
+// server. Commands using the root filesystem of the server are always
+// allowed, images must be listed by the images of the roles.
+func (c *cmdSrv) rootfsCheck(
+	rootfs string,
+	cert *x509.Certificate,
+) error {
+	if rootfs == "" {
+		return nil
+	}
+
+	role := tls.GetRole(
+		c.roles,
+		cert.Subject.Organization,
+		cert.Subject.OrganizationalUnit,
+	)
+
+	// TODO: The "admin" bypass is the same simple, insecure
+	// implementation as in roleCheck.
+	if _, full := role.Commands["*"]; !full {
+		if !role.AllowsImage(rootfs) {
+			return ErrAuthenticationFailure
+		}
+	}
+
+	return nil
+}
+
+// networkCheck handles the role evaluation for the given network mode using
+// the metadata from the supplied certificate and the roles defined in the
+// server. Commands without network access are always allowed, other modes
+// must be listed by the network modes of the roles.
+func (c *cmdSrv) networkCheck(
+	network sandbox.Network,
+	cert *x509.Certificate,
+) error {
+	if network == sandbox.NetworkNone {
+		return nil
+	}
+
+	role := tls.GetRole(
+		c.roles,
+		cert.Subject.Organization,
+		cert.Subject.OrganizationalUnit,
+	)
+
+	// TODO: The "admin" bypass is the same simple, insecure
+	// implementation as in roleCheck.
+	if _, full := role.Commands["*"]; !full {
+		if !role.AllowsNetwork(string(network)) {
+			return ErrAuthenticationFailure
+		}
+	}
+
+	return nil
+}
@@ -27,12 +27,33 @@ type CommandServiceClient interface {
 	// different operations. This allows for a very EXPLICIT contract for the
 	// client to follow.
 	Start(ctx context.Context, in *Command, opts ...grpc.CallOption) (*Process, error)
-	Stop(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error)
+	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Status, error)
 	Stat(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error)
+	// Pause freezes every process of the command until it is resumed using
+	// Resume. Stopping a paused command resumes it.
+	Pause(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error)
+	Resume(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error)
+	// Signal sends a signal to the command or its process group. The signals
+	// a client may send are limited by its roles.
+	Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*Status, error)
 	// I don't like the naming of the return stream here but I opted to go with a
 	// shortend command name and CommandOutput is self-describing though more
 	// verbose than I usually like.
-	Output(ctx context.Context, in *Process, opts ...grpc.CallOption) (CommandService_OutputClient, error)
+	Output(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (CommandService_OutputClient, error)
+	// Input streams data to the stdin of the command. The stdin of the command
+	// is closed once the client closes the stream.
+	Input(ctx context.Context, opts ...grpc.CallOption) (CommandService_InputClient, error)
+	// Attach connects the client to the terminal of the command. Keystrokes and
+	// terminal resize events are streamed to the command while its output is
+	// streamed back to the client until the command exits.
+	Attach(ctx context.Context, opts ...grpc.CallOption) (CommandService_AttachClient, error)
+	// Watch streams the status transitions of the command. The transitions
+	// which already happened are sent first and the stream ends once the
+	// command is released by the server.
+	Watch(ctx context.Context, in *Process, opts ...grpc.CallOption) (CommandService_WatchClient, error)
+	// List returns the commands selected by the request which the client is
+	// allowed to see.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type commandServiceClient struct {
@@ -52,7 +73,7 @@ func (c *commandServiceClient) Start(ctx context.Context, in *Command, opts ...g
 	return out, nil
 }
 
-func (c *commandServiceClient) Stop(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error) {
+func (c *commandServiceClient) Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Status, error) {
 	out := new(Status)
 	err := c.cc.Invoke(ctx, "/protobuf.CommandService/Stop", in, out, opts...)
 	if err != nil {
@@ -70,7 +91,34 @@ func (c *commandServiceClient) Stat(ctx context.Context, in *Process, opts ...gr
 	return out, nil
 }
 
-func (c *commandServiceClient) Output(ctx context.Context, in *Process, opts ...grpc.CallOption) (CommandService_OutputClient, error) {
+func (c *commandServiceClient) Pause(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/protobuf.CommandService/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandServiceClient) Resume(ctx context.Context, in *Process, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/protobuf.CommandService/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandServiceClient) Signal(ctx context.Context, in *SignalRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/protobuf.CommandService/Signal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandServiceClient) Output(ctx context.Context, in *OutputRequest, opts ...grpc.CallOption) (CommandService_OutputClient, error) {
 	stream, err := c.cc.NewStream(ctx, &CommandService_ServiceDesc.Streams[0], "/protobuf.CommandService/Output", opts...)
 	if err != nil {
 		return nil, err
@@ -102,6 +150,112 @@ func (x *commandServiceOutputClient) Recv() (*CommandOutput, error) {
 	return m, nil
 }
 
+func (c *commandServiceClient) Input(ctx context.Context, opts ...grpc.CallOption) (CommandService_InputClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CommandService_ServiceDesc.Streams[1], "/protobuf.CommandService/Input", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &commandServiceInputClient{stream}
+	return x, nil
+}
+
+type CommandService_InputClient interface {
+	Send(*CommandInput) error
+	CloseAndRecv() (*Status, error)
+	grpc.ClientStream
+}
+
+type commandServiceInputClient struct {
+	grpc.ClientStream
+}
+
+func (x *commandServiceInputClient) Send(m *CommandInput) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *commandServiceInputClient) CloseAndRecv() (*Status, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Status)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *commandServiceClient) Attach(ctx context.Context, opts ...grpc.CallOption) (CommandService_AttachClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CommandService_ServiceDesc.Streams[2], "/protobuf.CommandService/Attach", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &commandServiceAttachClient{stream}
+	return x, nil
+}
+
+type CommandService_AttachClient interface {
+	Send(*AttachRequest) error
+	Recv() (*CommandOutput, error)
+	grpc.ClientStream
+}
+
+type commandServiceAttachClient struct {
+	grpc.ClientStream
+}
+
+func (x *commandServiceAttachClient) Send(m *AttachRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *commandServiceAttachClient) Recv() (*CommandOutput, error) {
+	m := new(CommandOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *commandServiceClient) Watch(ctx context.Context, in *Process, opts ...grpc.CallOption) (CommandService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CommandService_ServiceDesc.Streams[3], "/protobuf.CommandService/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &commandServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CommandService_WatchClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type commandServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *commandServiceWatchClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *commandServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.CommandService/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CommandServiceServer is the server API for CommandService service.
 // All implementations must embed UnimplementedCommandServiceServer
 // for forward compatibility
@@ -111,12 +265,33 @@ type CommandServiceServer interface {
 	// different operations. This allows for a very EXPLICIT contract for the
 	// client to follow.
 	Start(context.Context, *Command) (*Process, error)
-	Stop(context.Context, *Process) (*Status, error)
+	Stop(context.Context, *StopRequest) (*Status, error)
 	Stat(context.Context, *Process) (*Status, error)
+	// Pause freezes every process of the command until it is resumed using
+	// Resume. Stopping a paused command resumes it.
+	Pause(context.Context, *Process) (*Status, error)
+	Resume(context.Context, *Process) (*Status, error)
+	// Signal sends a signal to the command or its process group. The signals
+	// a client may send are limited by its roles.
+	Signal(context.Context, *SignalRequest) (*Status, error)
 	// I don't like the naming of the return stream here but I opted to go with a
 	// shortend command name and CommandOutput is self-describing though more
 	// verbose than I usually like.
-	Output(*Process, CommandService_OutputServer) error
+	Output(*OutputRequest, CommandService_OutputServer) error
+	// Input streams data to the stdin of the command. The stdin of the command
+	// is closed once the client closes the stream.
+	Input(CommandService_InputServer) error
+	// Attach connects the client to the terminal of the command. Keystrokes and
+	// terminal resize events are streamed to the command while its output is
+	// streamed back to the client until the command exits.
+	Attach(CommandService_AttachServer) error
+	// Watch streams the status transitions of the command. The transitions
+	// which already happened are sent first and the stream ends once the
+	// command is released by the server.
+	Watch(*Process, CommandService_WatchServer) error
+	// List returns the commands selected by the request which the client is
+	// allowed to see.
+	List(context.Context, *ListRequest) (*ListResponse, error)
 	mustEmbedUnimplementedCommandServiceServer()
 }
 
@@ -127,15 +302,36 @@ type UnimplementedCommandServiceServer struct {
 func (UnimplementedCommandServiceServer) Start(context.Context, *Command) (*Process, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
 }
-func (UnimplementedCommandServiceServer) Stop(context.Context, *Process) (*Status, error) {
+func (UnimplementedCommandServiceServer) Stop(context.Context, *StopRequest) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
 }
 func (UnimplementedCommandServiceServer) Stat(context.Context, *Process) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
 }
-func (UnimplementedCommandServiceServer) Output(*Process, CommandService_OutputServer) error {
+func (UnimplementedCommandServiceServer) Pause(context.Context, *Process) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedCommandServiceServer) Resume(context.Context, *Process) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedCommandServiceServer) Signal(context.Context, *SignalRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Signal not implemented")
+}
+func (UnimplementedCommandServiceServer) Output(*OutputRequest, CommandService_OutputServer) error {
 	return status.Errorf(codes.Unimplemented, "method Output not implemented")
 }
+func (UnimplementedCommandServiceServer) Input(CommandService_InputServer) error {
+	return status.Errorf(codes.Unimplemented, "method Input not implemented")
+}
+func (UnimplementedCommandServiceServer) Attach(CommandService_AttachServer) error {
+	return status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
+func (UnimplementedCommandServiceServer) Watch(*Process, CommandService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedCommandServiceServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
 func (UnimplementedCommandServiceServer) mustEmbedUnimplementedCommandServiceServer() {}
 
 // UnsafeCommandServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -168,7 +364,7 @@ func _CommandService_Start_Handler(srv interface{}, ctx context.Context, dec fun
 }
 
 func _CommandService_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Process)
+	in := new(StopRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -180,7 +376,7 @@ func _CommandService_Stop_Handler(srv interface{}, ctx context.Context, dec func
 		FullMethod: "/protobuf.CommandService/Stop",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CommandServiceServer).Stop(ctx, req.(*Process))
+		return srv.(CommandServiceServer).Stop(ctx, req.(*StopRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -203,8 +399,62 @@ func _CommandService_Stat_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommandService_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Process)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandServiceServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.CommandService/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).Pause(ctx, req.(*Process))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Process)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.CommandService/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).Resume(ctx, req.(*Process))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandService_Signal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandServiceServer).Signal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.CommandService/Signal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).Signal(ctx, req.(*SignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CommandService_Output_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(Process)
+	m := new(OutputRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -224,6 +474,97 @@ func (x *commandServiceOutputServer) Send(m *CommandOutput) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _CommandService_Input_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CommandServiceServer).Input(&commandServiceInputServer{stream})
+}
+
+type CommandService_InputServer interface {
+	SendAndClose(*Status) error
+	Recv() (*CommandInput, error)
+	grpc.ServerStream
+}
+
+type commandServiceInputServer struct {
+	grpc.ServerStream
+}
+
+func (x *commandServiceInputServer) SendAndClose(m *Status) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *commandServiceInputServer) Recv() (*CommandInput, error) {
+	m := new(CommandInput)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _CommandService_Attach_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CommandServiceServer).Attach(&commandServiceAttachServer{stream})
+}
+
+type CommandService_AttachServer interface {
+	Send(*CommandOutput) error
+	Recv() (*AttachRequest, error)
+	grpc.ServerStream
+}
+
+type commandServiceAttachServer struct {
+	grpc.ServerStream
+}
+
+func (x *commandServiceAttachServer) Send(m *CommandOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *commandServiceAttachServer) Recv() (*AttachRequest, error) {
+	m := new(AttachRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _CommandService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Process)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CommandServiceServer).Watch(m, &commandServiceWatchServer{stream})
+}
+
+type CommandService_WatchServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type commandServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *commandServiceWatchServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CommandService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.CommandService/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandServiceServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CommandService_ServiceDesc is the grpc.ServiceDesc for CommandService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -243,6 +584,22 @@ var CommandService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stat",
 			Handler:    _CommandService_Stat_Handler,
 		},
+		{
+			MethodName: "Pause",
+			Handler:    _CommandService_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _CommandService_Resume_Handler,
+		},
+		{
+			MethodName: "Signal",
+			Handler:    _CommandService_Signal_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _CommandService_List_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -250,6 +607,22 @@ var CommandService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _CommandService_Output_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Input",
+			Handler:       _CommandService_Input_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Attach",
+			Handler:       _CommandService_Attach_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _CommandService_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api.proto",
 }
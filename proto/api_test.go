@@ -0,0 +1,229 @@
+package proto
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.benjiv.com/sandbox"
+	"go.benjiv.com/sandbox/internal/tls"
+)
+
+func newCert(org, unit string, serial int64) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Issuer:       pkix.Name{CommonName: "ca"},
+		Subject: pkix.Name{
+			Organization:       []string{org},
+			OrganizationalUnit: []string{unit},
+		},
+	}
+}
+
+func Test_accessCheck(t *testing.T) {
+	roles := tls.OrgRoles{
+		"it": {
+			"admin": {Commands: tls.Commands{"*": true}},
+			"user":  {Commands: tls.Commands{"ls": true}},
+			"ops":   {Commands: tls.Commands{"ls": true}, AnyJob: true},
+		},
+	}
+
+	owner := newCert("it", "user", 1)
+	status := sandbox.Status{Command: "ls", Identity: identity(owner)}
+
+	// Serial numbers are only unique per issuer.
+	otherIssuer := newCert("it", "user", 1)
+	otherIssuer.Issuer.CommonName = "other ca"
+
+	// Serial numbers longer than 64 bits are compared in full.
+	serial, _ := new(big.Int).SetString("0100000000000000000001", 16)
+	large := newCert("it", "user", 0)
+	large.SerialNumber = serial
+	largeOwner := newCert("it", "user", 0)
+	largeOwner.SerialNumber = new(big.Int).Add(serial, new(big.Int).Lsh(big.NewInt(1), 64))
+	largeStatus := sandbox.Status{Command: "ls", Identity: identity(largeOwner)}
+
+	tests := map[string]struct {
+		cert    *x509.Certificate
+		status  sandbox.Status
+		policy  Policy
+		allowed bool
+	}{
+		"owner":             {owner, status, PolicyOwner, true},
+		"other issuer":      {otherIssuer, status, PolicyOwner, false},
+		"large serial":      {large, largeStatus, PolicyOwner, false},
+		"large owner":       {largeOwner, largeStatus, PolicyOwner, true},
+		"other user":        {newCert("it", "user", 2), status, PolicyOwner, false},
+		"other user policy": {newCert("it", "user", 2), status, PolicyCommand, true},
+		"privileged":        {newCert("it", "ops", 3), status, PolicyOwner, true},
+		"admin":             {newCert("it", "admin", 4), status, PolicyOwner, true},
+		"other org":         {newCert("hr", "user", 5), status, PolicyCommand, false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := &cmdSrv{roles: roles, policy: test.policy}
+
+			err := c.accessCheck(test.status, test.cert)
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed to be %v, got %v", test.allowed, err)
+			}
+		})
+	}
+}
+
+func Test_deadlineCheck(t *testing.T) {
+	roles := tls.OrgRoles{
+		"it": {
+			"admin": {Commands: tls.Commands{"*": true}},
+			"user":  {Commands: tls.Commands{"ls": true}, Deadline: tls.Duration(time.Hour)},
+			"root":  {Commands: tls.Commands{"*": true}, Deadline: tls.Duration(time.Minute)},
+		},
+	}
+
+	tests := map[string]struct {
+		unit      string
+		requested time.Duration
+		expected  time.Duration
+		allowed   bool
+	}{
+		"unlimited":         {"admin", 0, 0, true},
+		"unlimited request": {"admin", time.Hour * 48, time.Hour * 48, true},
+		"wildcard":          {"root", time.Hour * 48, time.Hour * 48, true},
+		"default":           {"user", 0, time.Hour, true},
+		"within maximum":    {"user", time.Minute, time.Minute, true},
+		"exceeds maximum":   {"user", time.Hour * 2, 0, false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := &cmdSrv{roles: roles}
+
+			deadline, err := c.deadlineCheck(test.requested, newCert("it", test.unit, 1))
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed to be %v, got %v", test.allowed, err)
+			}
+
+			if deadline != test.expected {
+				t.Fatalf("expected deadline %s, got %s", test.expected, deadline)
+			}
+		})
+	}
+}
+
+func Test_networkCheck(t *testing.T) {
+	roles := tls.OrgRoles{
+		"it": {
+			"admin": {Commands: tls.Commands{"*": true}},
+			"user":  {Commands: tls.Commands{"ls": true}, Network: []string{"loopback"}},
+		},
+		"hr": {
+			"user": {Commands: tls.Commands{"ls": true}},
+		},
+	}
+
+	tests := map[string]struct {
+		org     string
+		unit    string
+		network sandbox.Network
+		allowed bool
+	}{
+		"admin":          {"it", "admin", sandbox.NetworkBridged, true},
+		"no network":     {"hr", "user", sandbox.NetworkNone, true},
+		"allowed mode":   {"it", "user", sandbox.NetworkLoopback, true},
+		"forbidden mode": {"it", "user", sandbox.NetworkBridged, false},
+		"no modes":       {"hr", "user", sandbox.NetworkLoopback, false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := &cmdSrv{roles: roles}
+
+			err := c.networkCheck(test.network, newCert(test.org, test.unit, 1))
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed to be %v, got %v", test.allowed, err)
+			}
+		})
+	}
+}
+
+func Test_rootfsCheck(t *testing.T) {
+	roles := tls.OrgRoles{
+		"it": {
+			"admin": {Commands: tls.Commands{"*": true}},
+			"user":  {Commands: tls.Commands{"ls": true}, Images: []string{"alpine"}},
+		},
+		"hr": {
+			"user": {Commands: tls.Commands{"ls": true}},
+		},
+	}
+
+	tests := map[string]struct {
+		org     string
+		unit    string
+		rootfs  string
+		allowed bool
+	}{
+		"admin":           {"it", "admin", "debian", true},
+		"host":            {"hr", "user", "", true},
+		"allowed image":   {"it", "user", "alpine", true},
+		"forbidden image": {"it", "user", "debian", false},
+		"no images":       {"hr", "user", "alpine", false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := &cmdSrv{roles: roles}
+
+			err := c.rootfsCheck(test.rootfs, newCert(test.org, test.unit, 1))
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed to be %v, got %v", test.allowed, err)
+			}
+		})
+	}
+}
+
+func Test_signalCheck(t *testing.T) {
+	roles := tls.OrgRoles{
+		"it": {
+			"admin": {Commands: tls.Commands{"*": true}},
+			"user":  {Commands: tls.Commands{"ls": true}, Signals: []string{"SIGHUP", "34"}},
+		},
+		"hr": {
+			"user": {Commands: tls.Commands{"ls": true}},
+		},
+	}
+
+	tests := map[string]struct {
+		org     string
+		unit    string
+		sig     syscall.Signal
+		allowed bool
+	}{
+		"admin":            {"it", "admin", syscall.SIGKILL, true},
+		"allowed signal":   {"it", "user", syscall.SIGHUP, true},
+		"allowed number":   {"it", "user", syscall.Signal(34), true},
+		"forbidden signal": {"it", "user", syscall.SIGKILL, false},
+		"no signals":       {"hr", "user", syscall.SIGHUP, false},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			c := &cmdSrv{roles: roles}
+
+			err := c.signalCheck(test.sig, newCert(test.org, test.unit, 1))
+			if (err == nil) != test.allowed {
+				t.Fatalf("expected allowed to be %v, got %v", test.allowed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrInvalidSignal is returned by Signal and ParseSignal for signals which
+// cannot be sent to a process.
+var ErrInvalidSignal = errors.New("invalid signal")
+
+// maxSignal is the largest signal number, the last real-time signal.
+const maxSignal = 64
+
+// signalNames are the names of the signals which are commonly sent to a
+// process. Other signals are named by their number.
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGABRT:  "SIGABRT",
+	syscall.SIGALRM:  "SIGALRM",
+	syscall.SIGCONT:  "SIGCONT",
+	syscall.SIGHUP:   "SIGHUP",
+	syscall.SIGINT:   "SIGINT",
+	syscall.SIGKILL:  "SIGKILL",
+	syscall.SIGPIPE:  "SIGPIPE",
+	syscall.SIGQUIT:  "SIGQUIT",
+	syscall.SIGSTOP:  "SIGSTOP",
+	syscall.SIGTERM:  "SIGTERM",
+	syscall.SIGTSTP:  "SIGTSTP",
+	syscall.SIGTTIN:  "SIGTTIN",
+	syscall.SIGTTOU:  "SIGTTOU",
+	syscall.SIGUSR1:  "SIGUSR1",
+	syscall.SIGUSR2:  "SIGUSR2",
+	syscall.SIGWINCH: "SIGWINCH",
+}
+
+// validSignal reports whether `sig` can be sent to a process.
+func validSignal(sig syscall.Signal) bool {
+	return sig > 0 && sig <= maxSignal
+}
+
+// SignalName returns the name of the signal, e.g. "SIGHUP", or its number
+// when the signal has no name.
+func SignalName(sig syscall.Signal) string {
+	if name, ok := signalNames[sig]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(sig))
+}
+
+// ParseSignal parses the name of a signal with or without the "SIG" prefix,
+// e.g. "SIGHUP" or "hup", or its number.
+func ParseSignal(name string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(name); err == nil {
+		if !validSignal(syscall.Signal(n)) {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, name)
+		}
+
+		return syscall.Signal(n), nil
+	}
+
+	upper := strings.ToUpper(name)
+	if !strings.HasPrefix(upper, "SIG") {
+		upper = "SIG" + upper
+	}
+
+	for sig, n := range signalNames {
+		if n == upper {
+			return sig, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrInvalidSignal, name)
+}
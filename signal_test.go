@@ -0,0 +1,39 @@
+package sandbox
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func Test_ParseSignal(t *testing.T) {
+	tests := map[string]syscall.Signal{
+		"SIGHUP":  syscall.SIGHUP,
+		"hup":     syscall.SIGHUP,
+		"SigUsr1": syscall.SIGUSR1,
+		"2":       syscall.SIGINT,
+		"34":      syscall.Signal(34),
+	}
+
+	for name, expected := range tests {
+		sig, err := ParseSignal(name)
+		if err != nil {
+			t.Fatalf("expected %q to parse, got %v", name, err)
+		}
+
+		if sig != expected {
+			t.Fatalf("expected %q to parse as %d, got %d", name, expected, sig)
+		}
+	}
+
+	for _, name := range []string{"", "0", "65", "-1", "SIGNOPE"} {
+		_, err := ParseSignal(name)
+		if !errors.Is(err, ErrInvalidSignal) {
+			t.Fatalf("expected ErrInvalidSignal for %q, got %v", name, err)
+		}
+	}
+
+	if SignalName(syscall.SIGUSR2) != "SIGUSR2" || SignalName(syscall.Signal(34)) != "34" {
+		t.Fatal("unexpected signal names")
+	}
+}
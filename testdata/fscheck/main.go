@@ -0,0 +1,28 @@
+// fscheck reports which of the paths provided as arguments can be read or
+// written by the process. It is used to test the root filesystem isolation
+// of the sandbox.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	for _, arg := range os.Args[1:] {
+		var err error
+		if strings.HasPrefix(arg, "write:") {
+			err = os.WriteFile(strings.TrimPrefix(arg, "write:"), []byte("x"), 0600)
+		} else {
+			_, err = os.ReadFile(arg)
+		}
+
+		result := "ok"
+		if err != nil {
+			result = "denied"
+		}
+
+		fmt.Printf("%s %s\n", arg, result)
+	}
+}
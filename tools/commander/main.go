@@ -49,7 +49,7 @@ func main() {
 		return
 	}
 
-	go func(id int) {
+	go func(id string) {
 		<-ctx.Done()
 		err = box.Stop(id)
 		if err != nil && err != context.Canceled {
@@ -58,7 +58,7 @@ func main() {
 		}
 	}(id)
 
-	output, err := box.Output(id)
+	output, err := box.Output(id, sandbox.Combined)
 	if err != nil {
 		fmt.Println(err)
 		exitcode = ERROR